@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// version is set at build time with -ldflags "-X main.version=v1.2.3".
+var version = "dev"
+
+// appVersion returns version, or the module version when the binary was
+// installed with go install and no version was set.
+func appVersion() string {
+	if version != "dev" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+
+	return version
+}
+
+// updateAbout handles keys while the about box is open.
+func (m model) updateAbout(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.confirmQuit()
+	case "esc", "A":
+		m.showAbout = false
+	}
+
+	return m, nil
+}
+
+// aboutView renders the version and what the app talks to, centered in the
+// terminal.
+func (m model) aboutView() string {
+	rate := "unknown until the first request"
+	if m.rate.Limit > 0 {
+		rate = fmt.Sprintf("%d/%d remaining", m.rate.Remaining, m.rate.Limit)
+	}
+	auth := "anonymous"
+	if m.client.Token != "" {
+		auth = "token"
+	}
+
+	fields := []struct {
+		label string
+		value string
+	}{
+		{"Version", appVersion()},
+		{"API", m.client.BaseURL},
+		{"Auth", auth},
+		{"Rate limit", rate},
+		{"Go", runtime.Version()},
+	}
+	lines := []string{labelStyle.Render("go-repositories"), ""}
+	for _, f := range fields {
+		lines = append(lines, labelStyle.Render(fmt.Sprintf("%-12s", f.label))+f.value)
+	}
+	lines = append(lines, "", helpStyle.Render("esc: close"))
+
+	box := detailsStyle.Render(strings.Join(lines, "\n"))
+	// the size is unknown until the first WindowSizeMsg
+	if m.width == 0 {
+		return box
+	}
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/YuriBrunetto/go-repositories/internal/github"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openBrowser opens url in the user's default browser. Only web links are
+// opened, since URLs such as homepages are set by whoever owns a repository.
+func openBrowser(url string) tea.Cmd {
+	return func() tea.Msg {
+		url, ok := webURL(url)
+		if !ok {
+			return actionErrMsg{action: "open the link", err: errors.New("it isn't a web page")}
+		}
+
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "darwin":
+			cmd = exec.Command("open", url)
+		case "windows":
+			// cmd /c start would interpret & and | inside the URL
+			cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+		default:
+			cmd = exec.Command("xdg-open", url)
+		}
+
+		if err := cmd.Start(); err != nil {
+			return actionErrMsg{action: "open the browser", err: err}
+		}
+		go cmd.Wait()
+
+		return nil
+	}
+}
+
+// webURL parses raw as an http or https URL with a host, reporting whether
+// it is one, and returns it with any unsafe characters escaped.
+func webURL(raw string) (string, bool) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+
+	return u.String(), true
+}
+
+// homepageURL returns the website of repo as a URL, or an empty string when
+// it has none or it isn't a web link. Homepages are often set without a
+// scheme.
+func homepageURL(repo github.Repository) string {
+	homepage := strings.TrimSpace(repo.Homepage)
+	if homepage == "" {
+		return ""
+	}
+	if !strings.Contains(homepage, "://") {
+		homepage = "https://" + homepage
+	}
+	homepage, ok := webURL(homepage)
+	if !ok {
+		return ""
+	}
+
+	return homepage
+}
+
+// openHomepage opens the website of repo, or says it has none.
+func (m model) openHomepage(repo github.Repository) (tea.Model, tea.Cmd) {
+	url := homepageURL(repo)
+	if url == "" {
+		m.notice = repo.Name + " has no website"
+		return m, nil
+	}
+
+	return m, openBrowser(url)
+}
+
+// openAllConfirm is how many repositories open at once without asking first.
+const openAllConfirm = 10
+
+// openAll opens every repository in the browser and reports how many were.
+func openAll(repositories []github.Repository) tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(repositories)+1)
+	for _, repo := range repositories {
+		cmds = append(cmds, openBrowser(repo.HTMLURL))
+	}
+	cmds = append(cmds, func() tea.Msg {
+		return noticeMsg(fmt.Sprintf("opened %d repos", len(repositories)))
+	})
+
+	return tea.Batch(cmds...)
+}
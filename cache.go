@@ -0,0 +1,261 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YuriBrunetto/go-repositories/internal/github"
+)
+
+// cacheTTL is how long cached repositories are used without asking the API.
+const cacheTTL = time.Hour
+
+// cacheEntry is the on-disk representation of a fetched repository list.
+type cacheEntry struct {
+	// Key is the cacheKey of the entry, telling hashed file names apart
+	Key          string              `json:"key"`
+	FetchedAt    time.Time           `json:"fetched_at"`
+	Kind         github.OwnerKind    `json:"kind"`
+	Repositories []github.Repository `json:"repositories"`
+	Limited      bool                `json:"limited,omitempty"`
+	Renames      []string            `json:"renames,omitempty"`
+}
+
+// cacheKey identifies a cached listing: the API it came from, who asked for
+// it, the username and the options it was listed with. The owner kind is left out since it is
+// cached with the entry.
+func cacheKey(client *github.Client, username string, opts github.ListOptions) string {
+	// the same username on GitHub Enterprise is someone else, and a token
+	// may see private repositories that anonymous runs mustn't be shown
+	key := strings.TrimSuffix(client.BaseURL, "/") + " " + username
+	if scope := tokenScope(client.Token); scope != "" {
+		key += "@" + scope
+	}
+	if opts.Starred {
+		key += "@starred"
+	}
+	if opts.Type != "" {
+		key += "@" + opts.Type
+	}
+	if opts.Limit > 0 {
+		key += "@limit" + strconv.Itoa(opts.Limit)
+	}
+
+	return key
+}
+
+// session keeps the listings of this run in memory, so looking someone up
+// again doesn't even read the disk. It is shared by concurrent fetches.
+var session = struct {
+	sync.Mutex
+	entries   map[string]cacheEntry
+	responses map[string]cachedResponse
+}{entries: map[string]cacheEntry{}, responses: map[string]cachedResponse{}}
+
+// keepInSession stores entry in the session cache.
+func keepInSession(key string, entry cacheEntry) {
+	// the model edits its repositories in place, so each gets a copy
+	entry.Repositories = slices.Clone(entry.Repositories)
+
+	session.Lock()
+	defer session.Unlock()
+	session.entries[strings.ToLower(key)] = entry
+}
+
+// cachePath returns the cache file for key.
+func cachePath(key string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(strings.ToLower(key)))
+	return filepath.Join(dir, "go-repositories", "listings", hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// readCache returns the cached repositories for key, if any, from this
+// session or from disk.
+func readCache(key string) (cacheEntry, bool) {
+	session.Lock()
+	entry, ok := session.entries[strings.ToLower(key)]
+	session.Unlock()
+	if ok {
+		entry.Repositories = slices.Clone(entry.Repositories)
+		return entry, true
+	}
+
+	path, err := cachePath(key)
+	if err != nil {
+		return cacheEntry{}, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cacheEntry{}, false
+	}
+
+	if err = json.Unmarshal(data, &entry); err != nil || !strings.EqualFold(entry.Key, key) {
+		return cacheEntry{}, false
+	}
+	keepInSession(key, entry)
+
+	return entry, true
+}
+
+// writeCache stores the repositories fetched for key, in this session and on
+// disk.
+func writeCache(key string, repositories Repositories) error {
+	entry := cacheEntry{
+		Key:          key,
+		FetchedAt:    time.Now(),
+		Kind:         repositories.kind,
+		Repositories: repositories.data,
+		Limited:      repositories.limited,
+		Renames:      repositories.renames,
+	}
+	keepInSession(key, entry)
+
+	path, err := cachePath(key)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return writePrivate(path, data)
+}
+
+// tokenScope returns a fingerprint of token keeping the caches of different
+// tokens apart without writing the token itself to disk, or an empty string
+// for anonymous requests.
+func tokenScope(token string) string {
+	if token == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+
+	return "token-" + hex.EncodeToString(sum[:6])
+}
+
+// writePrivate writes data to path where only the current user can read it,
+// since cached listings and responses can hold private repositories.
+func writePrivate(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+
+	// files written by earlier versions were readable by everyone
+	return os.Chmod(path, 0o600)
+}
+
+// cachedResponse is the on-disk representation of an API response kept to
+// be revalidated with its ETag.
+type cachedResponse struct {
+	URL  string `json:"url"`
+	ETag string `json:"etag"`
+	Body []byte `json:"body"`
+}
+
+// maxSessionResponses caps how many responses the session keeps in memory;
+// the disk still has the others.
+const maxSessionResponses = 100
+
+// keepResponse stores response in the session, making room by forgetting
+// another one when it is full.
+func keepResponse(key string, response cachedResponse) {
+	session.Lock()
+	defer session.Unlock()
+	if _, ok := session.responses[key]; !ok && len(session.responses) >= maxSessionResponses {
+		for other := range session.responses {
+			delete(session.responses, other)
+			break
+		}
+	}
+	session.responses[key] = response
+}
+
+// responseCache keeps API responses in the session and on disk, so that
+// refreshes only download what changed. It implements github.ResponseCache.
+type responseCache struct {
+	// scope is the tokenScope of the requests, as responses to a token
+	// can hold private repositories
+	scope string
+}
+
+// key identifies the response to url among those of every scope.
+func (c responseCache) key(url string) string {
+	if c.scope == "" {
+		return url
+	}
+
+	return c.scope + " " + url
+}
+
+// responsePath returns the cache file for the response kept under key.
+func responsePath(key string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, "go-repositories", "responses", hex.EncodeToString(sum[:])+".json"), nil
+}
+
+// Get returns the ETag and body of the response to url, if one was kept.
+func (c responseCache) Get(url string) (string, []byte, bool) {
+	key := c.key(url)
+	session.Lock()
+	response, ok := session.responses[key]
+	session.Unlock()
+	if ok {
+		return response.ETag, response.Body, true
+	}
+
+	path, err := responsePath(key)
+	if err != nil {
+		return "", nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, false
+	}
+	// a hash collision must not serve another URL's response
+	if err = json.Unmarshal(data, &response); err != nil || response.URL != url {
+		return "", nil, false
+	}
+
+	keepResponse(key, response)
+	return response.ETag, response.Body, true
+}
+
+// Set stores the response to url. Failing to write it to disk only costs a
+// download next time.
+func (c responseCache) Set(url, etag string, body []byte) {
+	key := c.key(url)
+	response := cachedResponse{URL: url, ETag: etag, Body: body}
+	keepResponse(key, response)
+
+	path, err := responsePath(key)
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	_ = writePrivate(path, data)
+}
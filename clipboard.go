@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/YuriBrunetto/go-repositories/internal/github"
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// noticeMsg is a short confirmation shown in the footer.
+type noticeMsg string
+
+// actionErrMsg reports in the footer that action, such as copying or
+// exporting, failed. Unlike errMsg it leaves the listing and any fetch in
+// progress alone.
+type actionErrMsg struct {
+	action string
+	err    error
+}
+
+// copyToClipboard writes text to the system clipboard and confirms it with
+// notice.
+func copyToClipboard(text, notice string) tea.Cmd {
+	return func() tea.Msg {
+		if err := clipboard.WriteAll(text); err != nil {
+			return actionErrMsg{action: "copy", err: err}
+		}
+
+		return noticeMsg(notice)
+	}
+}
+
+// copyCloneCommand copies the git command cloning repo.
+func copyCloneCommand(repo github.Repository) tea.Cmd {
+	return copyToClipboard("git clone "+cloneURL(repo), "copied the clone command for "+repo.FullName)
+}
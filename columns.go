@@ -0,0 +1,358 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/YuriBrunetto/go-repositories/internal/github"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// column describes a table column and how a repository fills it.
+type column struct {
+	title string
+	// weight is the share of the table width the column takes
+	weight int
+	value  func(repo github.Repository) string
+}
+
+// defaultEmptyDescription is shown for repositories without a description
+// unless --empty-description says otherwise.
+const defaultEmptyDescription = "-no description-"
+
+// emptyDescription is shown for repositories without a description.
+var emptyDescription = defaultEmptyDescription
+
+var (
+	ownerColumn = column{"Owner", 14, func(repo github.Repository) string {
+		return repo.Owner.Login
+	}}
+	nameColumn = column{"Name", 22, func(repo github.Repository) string {
+		return repo.Name
+	}}
+	descriptionColumn = column{"Description", 37, func(repo github.Repository) string {
+		if repo.Description == "" {
+			return emptyDescription
+		}
+		return singleLine(repo.Description)
+	}}
+	languageColumn = column{"Language", 12, func(repo github.Repository) string {
+		if repo.Language == "" {
+			return "-"
+		}
+		return repo.Language
+	}}
+	starsColumn = column{"Stars", 9, func(repo github.Repository) string {
+		return "⭐ " + formatInt(repo.StargazersCount)
+	}}
+	forksColumn = column{"Forks", 10, func(repo github.Repository) string {
+		return formatThousands(repo.ForksCount)
+	}}
+	issuesColumn = column{"Issues", 10, func(repo github.Repository) string {
+		return formatThousands(repo.OpenIssuesCount)
+	}}
+	releaseColumn = column{"Release", 12, func(repo github.Repository) string {
+		if repo.LatestRelease == "" {
+			return "-"
+		}
+		return repo.LatestRelease
+	}}
+	sizeColumn = column{"Size", 10, func(repo github.Repository) string {
+		return formatSize(repo.Size)
+	}}
+	branchColumn = column{"Branch", 10, func(repo github.Repository) string {
+		if repo.DefaultBranch == "" {
+			return "-"
+		}
+		return repo.DefaultBranch
+	}}
+	licenseColumn = column{"License", 12, func(repo github.Repository) string {
+		switch repo.License.SPDXID {
+		case "":
+			return "-"
+		case "NOASSERTION":
+			return "other"
+		}
+		return repo.License.SPDXID
+	}}
+)
+
+// dateColumn returns a column showing the date picked by date in the format
+// chosen on m.
+func (m model) dateColumn(title string, date func(repo github.Repository) time.Time) column {
+	return column{title, 12, func(repo github.Repository) string {
+		return m.formatDate(date(repo))
+	}}
+}
+
+// formatDate renders t as a date when absoluteDates is set, and relative to
+// now otherwise.
+func (m model) formatDate(t time.Time) string {
+	if m.absoluteDates && !t.IsZero() {
+		return t.Format("2006-01-02")
+	}
+
+	return shortAge(t, time.Now())
+}
+
+func updatedAt(repo github.Repository) time.Time { return repo.UpdatedAt }
+
+func createdAt(repo github.Repository) time.Time { return repo.CreatedAt }
+
+// defaultShownColumns are the optional columns shown until others are
+// picked.
+var defaultShownColumns = []string{"Description", "Language", "Stars", "Updated"}
+
+// shownColumnsFrom returns the set of column titles, falling back to the
+// default columns when there are none.
+func shownColumnsFrom(titles []string) map[string]bool {
+	if len(titles) == 0 {
+		titles = defaultShownColumns
+	}
+	shown := map[string]bool{}
+	for _, title := range titles {
+		shown[title] = true
+	}
+
+	return shown
+}
+
+// optionalColumns are the columns that can be shown or hidden, in table
+// order. The name is always shown.
+func (m model) optionalColumns() []column {
+	return []column{
+		descriptionColumn,
+		languageColumn,
+		starsColumn,
+		m.dateColumn("Updated", updatedAt),
+		forksColumn,
+		issuesColumn,
+		releaseColumn,
+		licenseColumn,
+		sizeColumn,
+		branchColumn,
+		m.dateColumn("Created", createdAt),
+	}
+}
+
+// columns returns the columns currently shown in the table.
+func (m model) columns() []column {
+	columns := []column{m.nameColumn()}
+	if m.repositories.users > 1 {
+		columns = append([]column{ownerColumn}, columns...)
+	}
+	for _, c := range m.optionalColumns() {
+		if m.shownColumns[c.title] {
+			columns = append(columns, c)
+		}
+	}
+
+	return columns
+}
+
+// nameColumn is nameColumn with favorites and private repositories marked.
+func (m model) nameColumn() column {
+	return column{nameColumn.title, nameColumn.weight, func(repo github.Repository) string {
+		name := repo.Name
+		if repo.Private {
+			name = "🔒 " + name
+		}
+		if m.favorites[favoriteKey(repo)] {
+			name = "♥ " + name
+		}
+		return name
+	}}
+}
+
+// toggleColumn shows or hides the optional column under the menu cursor.
+func (m *model) toggleColumn() {
+	title := m.optionalColumns()[m.columnCursor].title
+
+	// the map is copied so earlier models keep their columns
+	shown := maps.Clone(m.shownColumns)
+	shown[title] = !shown[title]
+	m.shownColumns = shown
+	m.updateRows()
+}
+
+// updateColumnsMenu handles the keys of the column menu.
+func (m model) updateColumnsMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.confirmQuit()
+	case "esc", "c":
+		m.viewMode = viewTable
+		if m.persist {
+			s := loadSettings()
+			s.Columns = m.shownTitles()
+			_ = saveSettings(s)
+		}
+	case "up", "k":
+		m.columnCursor = max(0, m.columnCursor-1)
+	case "down", "j":
+		m.columnCursor = min(len(m.optionalColumns())-1, m.columnCursor+1)
+	case " ", "x", "enter":
+		m.toggleColumn()
+	}
+
+	return m, nil
+}
+
+// shownTitles lists the titles of the optional columns shown, in table order.
+func (m model) shownTitles() []string {
+	titles := []string{}
+	for _, c := range m.optionalColumns() {
+		if m.shownColumns[c.title] {
+			titles = append(titles, c.title)
+		}
+	}
+
+	return titles
+}
+
+// columnsMenuView renders the optional columns with their visibility.
+func (m model) columnsMenuView() string {
+	lines := []string{labelStyle.Render("Columns"), ""}
+	for i, c := range m.optionalColumns() {
+		check := "[ ]"
+		if m.shownColumns[c.title] {
+			check = "[x]"
+		}
+		line := fmt.Sprintf("%s %s", check, c.title)
+		if i == m.columnCursor {
+			line = labelStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", helpStyle.Render("space: toggle • esc: done"))
+
+	return detailsStyle.Render(strings.Join(lines, "\n"))
+}
+
+// tableColumns returns the table columns sized to fill width, sharing it by
+// the weight of each column.
+func tableColumns(columns []column, width int) []table.Column {
+	// every cell is padded by one space on each side
+	available := width - 2*len(columns)
+	total := 0
+	for _, c := range columns {
+		total += c.weight
+	}
+
+	result := make([]table.Column, len(columns))
+	remaining := available
+	for i, c := range columns {
+		result[i].Title = c.title
+		if i == len(columns)-1 {
+			result[i].Width = remaining
+		} else {
+			result[i].Width = c.weight * available / total
+			remaining -= result[i].Width
+		}
+		if result[i].Width < 1 {
+			result[i].Width = 1
+		}
+	}
+
+	return result
+}
+
+// cellStyle returns the style of a cell of the column titled title showing
+// text in width cells, if it has one.
+func cellStyle(title, text string, width int) (lipgloss.Style, bool) {
+	switch title {
+	case languageColumn.title:
+		return languageStyle(text), text != ""
+	case descriptionColumn.title:
+		// dims the placeholder so it doesn't read as a description
+		return helpStyle, text == truncate(emptyDescription, width)
+	}
+
+	return lipgloss.Style{}, false
+}
+
+// styleCells styles the cells of the rendered table view that have a style.
+// The table truncates cells without regard for escape codes, so styles are
+// added to its output rather than to the rows. Lines already styled, such as
+// the selected row, are left alone.
+func (m model) styleCells(view string) string {
+	if m.noColor {
+		return view
+	}
+
+	columns := tableColumns(m.columns(), m.tableWidth())
+	starts := make([]int, len(columns))
+	start := 0
+	for i, c := range columns {
+		// every cell is padded by one space on each side
+		starts[i] = start + 1
+		start += c.Width + 2
+	}
+
+	lines := strings.Split(view, "\n")
+	// the first line holds the column titles
+	for i := 1; i < len(lines); i++ {
+		if strings.Contains(lines[i], "\x1b") {
+			continue
+		}
+		// styling from the right keeps the cells to the left unstyled
+		for j := len(columns) - 1; j >= 0; j-- {
+			before, cell, after := cutCells(lines[i], starts[j], columns[j].Width)
+			text := strings.TrimSpace(cell)
+			if style, ok := cellStyle(columns[j].Title, text, columns[j].Width); ok {
+				lines[i] = before + strings.Replace(cell, text, style.Render(text), 1) + after
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// cutCells splits line around the width terminal cells starting at start.
+func cutCells(line string, start, width int) (before, cut, after string) {
+	from, to := len(line), len(line)
+	cells := 0
+	for i, r := range line {
+		if cells == start && from == len(line) {
+			from = i
+		}
+		if cells >= start+width {
+			to = i
+			break
+		}
+		cells += runewidth.RuneWidth(r)
+	}
+
+	return line[:from], line[from:to], line[to:]
+}
+
+// clippedDescription returns the description of repo when the table cuts it
+// short, so it can be shown in full elsewhere.
+func (m model) clippedDescription(repo github.Repository) (string, bool) {
+	columns := m.columns()
+	for i, c := range tableColumns(columns, m.tableWidth()) {
+		if columns[i].title != descriptionColumn.title {
+			continue
+		}
+		description := cellText(descriptionColumn.value(repo))
+		return description, runewidth.StringWidth(description) > c.Width
+	}
+
+	return "", false
+}
+
+// tableWidth is the width available to the table inside its border.
+func (m model) tableWidth() int {
+	if m.width == 0 {
+		return defaultWidth
+	}
+
+	return min(defaultWidth, m.width-2)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/YuriBrunetto/go-repositories/internal/github"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type viewMode int
+
+const (
+	viewTable viewMode = iota
+	viewDetails
+	viewColumns
+	viewSort
+)
+
+// detailsWidth is the width of the details box.
+func (m model) detailsWidth() int {
+	return min(defaultWidth, max(m.width, 40)) - 4
+}
+
+// detailsView renders every known field of repo.
+func (m model) detailsView(repo github.Repository) string {
+	description := repo.Description
+	if description == "" {
+		description = helpStyle.Render(emptyDescription)
+	}
+	language := repo.Language
+	if language == "" {
+		language = "-"
+	}
+
+	// wrap long values such as descriptions inside the box
+	width := m.detailsWidth()
+	valueWidth := width - 12 - 4
+
+	fields := []struct {
+		label string
+		value string
+	}{
+		{"Name", repo.FullName},
+		{"Visibility", visibilityView(repo)},
+		{"Description", description},
+		{"Stars", formatThousands(repo.StargazersCount)},
+		{"Forks", formatThousands(repo.ForksCount)},
+		{"Open issues", formatThousands(repo.OpenIssuesCount)},
+		{"Size", sizeColumn.value(repo)},
+		{"Language", language},
+		{"Topics", topicsView(repo.Topics, valueWidth)},
+		{"Created", m.dateView(repo.CreatedAt)},
+		{"Updated", m.dateView(repo.UpdatedAt)},
+		{"Release", releaseColumn.value(repo)},
+		{"License", licenseColumn.value(repo)},
+		{"Branch", branchColumn.value(repo)},
+		{"URL", repo.HTMLURL},
+		{"Website", homepageView(repo)},
+		{"Clone", cloneURL(repo)},
+	}
+
+	lines := []string{}
+	for _, f := range fields {
+		label := labelStyle.Render(fmt.Sprintf("%-12s", f.label))
+		value := lipgloss.NewStyle().Width(valueWidth).Render(f.value)
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, label, value))
+	}
+
+	return detailsStyle.Width(width).Render(strings.Join(lines, "\n"))
+}
+
+// visibilityView tells whether repo is public or private.
+func visibilityView(repo github.Repository) string {
+	if repo.Private {
+		return "🔒 private"
+	}
+
+	return "public"
+}
+
+// homepageView returns the website of repo, or "-" when it has none.
+func homepageView(repo github.Repository) string {
+	if url := homepageURL(repo); url != "" {
+		return url
+	}
+
+	return "-"
+}
+
+// cloneURL returns the HTTPS URL git clones repo from.
+func cloneURL(repo github.Repository) string {
+	if repo.HTMLURL != "" {
+		// Enterprise repositories are cloned from their own host
+		return repo.HTMLURL + ".git"
+	}
+
+	return "https://github.com/" + repo.FullName + ".git"
+}
+
+// topicsView renders topics as chips, starting a new line rather than
+// splitting a chip when width is reached.
+func topicsView(topics []string, width int) string {
+	if len(topics) == 0 {
+		return "-"
+	}
+
+	lines := []string{}
+	line := ""
+	for _, topic := range topics {
+		chip := topicStyle.Render(topic)
+		if line != "" && lipgloss.Width(line)+1+lipgloss.Width(chip) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += chip
+	}
+
+	return strings.Join(append(lines, line), "\n")
+}
+
+// dateView shows t both relative to now and as a date, leading with the
+// format chosen on m.
+func (m model) dateView(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	relative, absolute := timeAgo(t, time.Now()), t.Format("2006-01-02 15:04")
+	if m.absoluteDates {
+		return fmt.Sprintf("%s (%s)", absolute, relative)
+	}
+
+	return fmt.Sprintf("%s (%s)", relative, absolute)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YuriBrunetto/go-repositories/internal/github"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// enrichWorkers caps how many per-repository requests run at once.
+const enrichWorkers = 5
+
+// enrichmentMsg carries the extra metadata fetched for one repository.
+type enrichmentMsg struct {
+	fullName      string
+	latestRelease string
+	// next waits for the following enrichment
+	next tea.Cmd
+}
+
+// releases remembers the latest release of every repository enriched during
+// this run, an empty one when it has none, so showing a listing again costs
+// no more requests.
+var releases = struct {
+	sync.Mutex
+	tags map[string]string
+}{tags: map[string]string{}}
+
+// knownRelease returns the latest release found for the repository called
+// fullName, and whether it was looked up at all.
+func knownRelease(fullName string) (string, bool) {
+	releases.Lock()
+	defer releases.Unlock()
+	tag, ok := releases.tags[strings.ToLower(fullName)]
+
+	return tag, ok
+}
+
+func rememberRelease(fullName, tag string) {
+	releases.Lock()
+	defer releases.Unlock()
+	releases.tags[strings.ToLower(fullName)] = tag
+}
+
+// fillKnownReleases sets the releases already looked up on repositories.
+func fillKnownReleases(repositories []github.Repository) {
+	for i, repo := range repositories {
+		if tag, ok := knownRelease(repo.FullName); ok && repo.LatestRelease == "" {
+			repositories[i].LatestRelease = tag
+		}
+	}
+}
+
+// enrichRepositories fetches the latest release of every repository whose
+// release isn't known yet with a bounded pool of workers, delivering results
+// one enrichmentMsg at a time. A failing repository is skipped without
+// stopping the others, and cancelling ctx stops the pool.
+func enrichRepositories(ctx context.Context, client *github.Client, repositories []github.Repository, timeout time.Duration) tea.Cmd {
+	pending := []string{}
+	for _, repo := range repositories {
+		if _, ok := knownRelease(repo.FullName); !ok && repo.LatestRelease == "" {
+			pending = append(pending, repo.FullName)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		jobs := make(chan string)
+		results := make(chan enrichmentMsg)
+
+		var wg sync.WaitGroup
+		for i := 0; i < min(enrichWorkers, len(pending)); i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for fullName := range jobs {
+					reqCtx, cancel := context.WithTimeout(ctx, timeout)
+					release, err := client.FetchLatestRelease(reqCtx, fullName)
+					cancel()
+					if err != nil {
+						continue
+					}
+					rememberRelease(fullName, release)
+					if release == "" {
+						continue
+					}
+					select {
+					case results <- enrichmentMsg{fullName: fullName, latestRelease: release}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+
+		go func() {
+			feed := func() {
+				for _, fullName := range pending {
+					select {
+					case jobs <- fullName:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			feed()
+			close(jobs)
+			wg.Wait()
+			close(results)
+		}()
+
+		return waitForEnrichment(ctx, results)()
+	}
+}
+
+// stopEnriching cancels the release lookups still running for the listing.
+func (m *model) stopEnriching() {
+	if m.stopEnrichment != nil {
+		m.stopEnrichment()
+		m.stopEnrichment = nil
+	}
+}
+
+// waitForEnrichment returns the next result from results, or nil once every
+// repository has been handled or ctx is cancelled.
+func waitForEnrichment(ctx context.Context, results <-chan enrichmentMsg) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case msg, ok := <-results:
+			if !ok {
+				return nil
+			}
+			msg.next = waitForEnrichment(ctx, results)
+			return msg
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
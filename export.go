@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/YuriBrunetto/go-repositories/internal/github"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// exportPath is the file the displayed repositories are exported to.
+const exportPath = "repos.csv"
+
+// exportCSV writes repositories to exportPath in the working directory.
+func exportCSV(repositories []github.Repository) tea.Cmd {
+	return func() tea.Msg {
+		f, err := os.Create(exportPath)
+		if err != nil {
+			return actionErrMsg{action: "export to " + exportPath, err: err}
+		}
+		defer f.Close()
+
+		w := csv.NewWriter(f)
+		w.Write([]string{"Name", "Description", "Stars", "URL"})
+		for _, repo := range repositories {
+			w.Write([]string{
+				repo.Name, repo.Description, strconv.Itoa(repo.StargazersCount), repo.HTMLURL,
+			})
+		}
+		w.Flush()
+		if err = w.Error(); err != nil {
+			return actionErrMsg{action: "export to " + exportPath, err: err}
+		}
+		if err = f.Close(); err != nil {
+			return actionErrMsg{action: "export to " + exportPath, err: err}
+		}
+
+		return noticeMsg("exported to " + exportPath)
+	}
+}
+
+// markdownList formats repositories as a markdown bullet list of links
+// followed by their descriptions.
+func markdownList(repositories []github.Repository) string {
+	var b strings.Builder
+	for _, repo := range repositories {
+		fmt.Fprintf(&b, "- [%s](%s)", repo.Name, repo.HTMLURL)
+		if description := singleLine(repo.Description); description != "" {
+			b.WriteString(" — " + description)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
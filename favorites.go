@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/YuriBrunetto/go-repositories/internal/github"
+)
+
+// favoritesPath returns the favorites file under the user config directory.
+func favoritesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "go-repositories", "favorites.json"), nil
+}
+
+// favoriteKey identifies repo in the favorites, as its lowercased owner/name.
+func favoriteKey(repo github.Repository) string {
+	return strings.ToLower(repo.FullName)
+}
+
+// loadFavorites reads the saved favorites, returning an empty set when there
+// are none yet.
+func loadFavorites() map[string]bool {
+	favorites := map[string]bool{}
+	path, err := favoritesPath()
+	if err != nil {
+		return favorites
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return favorites
+	}
+	names := []string{}
+	if err = json.Unmarshal(data, &names); err != nil {
+		return favorites
+	}
+	for _, name := range names {
+		favorites[strings.ToLower(name)] = true
+	}
+
+	return favorites
+}
+
+// saveFavorites writes favorites to the favorites file as a sorted list.
+func saveFavorites(favorites map[string]bool) error {
+	path, err := favoritesPath()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(favorites))
+	for name := range favorites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	data, err := json.MarshalIndent(names, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// toggleFavorite marks or unmarks repo as a favorite, saving the change
+// unless nothing is persisted.
+func (m *model) toggleFavorite(repo github.Repository) {
+	// the map is copied so earlier models keep their favorites
+	favorites := maps.Clone(m.favorites)
+	key := favoriteKey(repo)
+	if favorites[key] {
+		delete(favorites, key)
+		m.notice = "removed " + repo.FullName + " from favorites"
+	} else {
+		favorites[key] = true
+		m.notice = "added " + repo.FullName + " to favorites"
+	}
+	m.favorites = favorites
+
+	if m.persist {
+		if err := saveFavorites(favorites); err != nil {
+			m.notice = "couldn't save favorites: " + err.Error()
+		}
+	}
+	m.updateRows()
+}
+
+// pinFavorites moves the favorites in repositories to the top, keeping the
+// order of both groups.
+func (m model) pinFavorites(repositories []github.Repository) {
+	sort.SliceStable(repositories, func(i, j int) bool {
+		return m.favorites[favoriteKey(repositories[i])] && !m.favorites[favoriteKey(repositories[j])]
+	})
+}
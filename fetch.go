@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/YuriBrunetto/go-repositories/internal/github"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultTimeout bounds how long a whole fetch may take.
+const defaultTimeout = 10 * time.Second
+
+// fetchRepositories lists the repositories of username as a Repositories
+// message, or an errMsg when the fetch fails. Cancelling ctx abandons the
+// request.
+func fetchRepositories(ctx context.Context, client *github.Client, username string, opts github.ListOptions, timeout time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		// one repository past the limit tells whether the limit was hit
+		limit := opts.Limit
+		if limit > 0 {
+			opts.Limit++
+		}
+		redirected := false
+		opts.Redirected = func(string, string) {
+			redirected = true
+		}
+
+		// the pages before a failing one are still worth showing
+		repositories, kind, err := client.FetchRepos(ctx, username, opts)
+		var pageErr *github.PageError
+		var warnings []string
+		if errors.As(err, &pageErr) {
+			warnings = append(warnings, fmt.Sprintf("partial results: page %d failed (%v)", pageErr.Page, describeError(pageErr.Err, username, timeout)))
+		} else if err != nil {
+			return newErrMsg(err, describeError(err, username, timeout))
+		}
+
+		limited := limit > 0 && len(repositories) > limit
+		if limited {
+			repositories = repositories[:limit]
+		}
+
+		var renames []string
+		if redirected && !opts.Starred {
+			renames = append(renames, renamedNote(username, repositories))
+		}
+
+		return Repositories{data: repositories, kind: kind, starred: opts.Starred, users: 1, limited: limited, renames: renames, warnings: warnings}
+	}
+}
+
+// fetchRepository loads the single repository called fullName (owner/name)
+// as a Repositories message holding just it, or an errMsg when it fails.
+func fetchRepository(ctx context.Context, client *github.Client, fullName string, timeout time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		repo, err := client.FetchRepo(ctx, fullName)
+		if err != nil {
+			described := describeError(err, fullName, timeout)
+			if errors.Is(err, github.ErrNotFound) {
+				described = fmt.Errorf("repository '%s' not found", fullName)
+			}
+			msg := newErrMsg(err, described)
+			msg.rate = client.Rate()
+			return msg
+		}
+
+		return Repositories{data: []github.Repository{repo}, kind: github.OwnerAny, users: 1, single: true, rate: client.Rate()}
+	}
+}
+
+// newErrMsg reports err, shown as described, with the request that failed
+// when GitHub answered it.
+func newErrMsg(err, described error) errMsg {
+	msg := errMsg{err: described, offline: isOffline(err)}
+	var statusErr *github.StatusError
+	if errors.As(err, &statusErr) {
+		msg.method = statusErr.Method
+		msg.url = statusErr.URL
+		msg.statusCode = statusErr.StatusCode
+	}
+
+	return msg
+}
+
+// renamedNote tells that username was redirected, naming the account it now
+// belongs to when its repositories say so.
+func renamedNote(username string, repositories []github.Repository) string {
+	for _, repo := range repositories {
+		if login := repo.Owner.Login; login != "" && !strings.EqualFold(login, username) {
+			return fmt.Sprintf("'%s' is now '%s'", username, login)
+		}
+	}
+
+	return fmt.Sprintf("'%s' was redirected to another account", username)
+}
+
+// describeError replaces not found errors and timeouts with messages naming
+// the missing account or the exceeded timeout.
+func describeError(err error, username string, timeout time.Duration) error {
+	if errors.Is(err, github.ErrNotFound) {
+		return fmt.Errorf("user '%s' not found", username)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("request timed out after %s", timeout)
+	}
+	if isOffline(err) {
+		return errors.New("can't reach GitHub — check your connection")
+	}
+
+	return err
+}
+
+// isOffline reports whether err means GitHub couldn't be reached at all, as
+// opposed to GitHub answering with an error.
+func isOffline(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ENETUNREACH) || errors.Is(err, syscall.EHOSTUNREACH)
+}
+
+// fetch returns a command loading the repositories of the current username,
+// which may be a comma-separated list of users whose repositories are merged.
+// Each user is served from the disk cache while it is fresh unless refresh is
+// set, and the cache is also used as a fallback when the API can't be
+// reached.
+func (m model) fetch(refresh bool) tea.Cmd {
+	username := m.username
+	if fullName, ok := parseRepoName(username); ok {
+		return fetchRepository(m.ctx, m.client, fullName, m.timeout)
+	}
+	usernames := strings.Split(username, ",")
+	persist := m.persist
+	ctx, client, timeout := m.ctx, m.client, m.timeout
+	opts := github.ListOptions{Kind: m.ownerKind, Starred: m.starred, Limit: m.limit, Type: m.repoType}
+
+	// failing to persist doesn't make the fetch itself fail
+	remember := func() {
+		if persist {
+			s := loadSettings()
+			s.LastUsername = username
+			_ = saveSettings(s)
+		}
+	}
+
+	load := func(report func(user int) func(pages int, repositories []github.Repository)) tea.Msg {
+		if len(usernames) == 1 {
+			opts.Progress = report(0)
+			msg := loadUser(ctx, client, username, opts, timeout, refresh)
+			if _, ok := msg.(Repositories); ok {
+				remember()
+			}
+			return msg
+		}
+
+		msgs := make([]tea.Msg, len(usernames))
+		var wg sync.WaitGroup
+		for i, name := range usernames {
+			wg.Add(1)
+			go func(i int, name string) {
+				defer wg.Done()
+				userOpts := opts
+				userOpts.Kind = github.OwnerAny
+				userOpts.Progress = report(i)
+				msgs[i] = loadUser(ctx, client, name, userOpts, timeout, refresh)
+			}(i, name)
+		}
+		wg.Wait()
+
+		merged := Repositories{kind: github.OwnerAny, starred: opts.Starred, users: len(usernames)}
+		var failure errMsg
+		failures := 0
+		for i, msg := range msgs {
+			switch msg := msg.(type) {
+			case Repositories:
+				merged.data = append(merged.data, msg.data...)
+				merged.limited = merged.limited || msg.limited
+				merged.renames = append(merged.renames, msg.renames...)
+				for _, warning := range msg.warnings {
+					merged.warnings = append(merged.warnings, fmt.Sprintf("%s: %s", usernames[i], warning))
+				}
+			case errMsg:
+				failure = msg
+				failures++
+				merged.warnings = append(merged.warnings, fmt.Sprintf("%s: %s", usernames[i], msg.Error()))
+			}
+		}
+		if failures == len(usernames) {
+			return failure
+		}
+
+		remember()
+		return merged
+	}
+
+	// the quota is read once every request of the fetch is done
+	withRate := func(report func(user int) func(pages int, repositories []github.Repository)) tea.Msg {
+		switch msg := load(report).(type) {
+		case Repositories:
+			msg.rate = client.Rate()
+			return msg
+		case errMsg:
+			msg.rate = client.Rate()
+			return msg
+		default:
+			return msg
+		}
+	}
+
+	return streamProgress(len(usernames), withRate)
+}
+
+// fetchProgress counts what a fetch has loaded so far.
+type fetchProgress struct {
+	pages        int
+	repositories int
+}
+
+func (p fetchProgress) String() string {
+	pages := "pages"
+	if p.pages == 1 {
+		pages = "page"
+	}
+
+	return fmt.Sprintf("fetched %d %s, %d repos...", p.pages, pages, p.repositories)
+}
+
+// progressMsg reports the progress of a running fetch along with the
+// repositories it has loaded so far, so they can be browsed before it ends.
+type progressMsg struct {
+	fetchProgress
+	data []github.Repository
+	// users is how many users the repositories are merged from
+	users int
+	// next waits for the following progress or the fetch result
+	next tea.Cmd
+}
+
+// streamProgress runs load, which fetches for users users, delivering a
+// progressMsg after every page and then the result of load. report gives the
+// progress callback of each user; their pages are merged.
+func streamProgress(users int, load func(report func(user int) func(pages int, repositories []github.Repository)) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		messages := make(chan tea.Msg, 1)
+		pages := make([]int, users)
+		loaded := make([][]github.Repository, users)
+		var mu sync.Mutex
+
+		report := func(user int) func(pages int, repositories []github.Repository) {
+			return func(userPages int, repositories []github.Repository) {
+				mu.Lock()
+				defer mu.Unlock()
+				pages[user], loaded[user] = userPages, repositories
+
+				msg := progressMsg{users: users}
+				for i := range loaded {
+					msg.pages += pages[i]
+					msg.data = append(msg.data, loaded[i]...)
+				}
+				msg.repositories = len(msg.data)
+				// skipped while the previous progress is still waiting to be
+				// shown, the next one carries everything anyway
+				select {
+				case messages <- msg:
+				default:
+				}
+			}
+		}
+		go func() {
+			messages <- load(report)
+		}()
+
+		return waitForProgress(messages)()
+	}
+}
+
+// waitForProgress returns the next message from messages, chaining another
+// wait after progress until the result arrives.
+func waitForProgress(messages <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg := <-messages
+		if progress, ok := msg.(progressMsg); ok {
+			progress.next = waitForProgress(messages)
+			return progress
+		}
+
+		return msg
+	}
+}
+
+// loadUser returns the repositories of a single user as a Repositories
+// message, going through the disk cache.
+func loadUser(ctx context.Context, client *github.Client, username string, opts github.ListOptions, timeout time.Duration, refresh bool) tea.Msg {
+	key := cacheKey(client, username, opts)
+	entry, cached := readCache(key)
+	fromCache := func() Repositories {
+		return Repositories{
+			data:     entry.Repositories,
+			kind:     entry.Kind,
+			starred:  opts.Starred,
+			cachedAt: entry.FetchedAt,
+			users:    1,
+			limited:  entry.Limited,
+			renames:  entry.Renames,
+		}
+	}
+	if cached && !refresh && time.Since(entry.FetchedAt) < cacheTTL {
+		return fromCache()
+	}
+
+	msg := fetchRepositories(ctx, client, username, opts, timeout)()
+	switch msg := msg.(type) {
+	case Repositories:
+		// partial results would stand in for the full list for a while
+		if len(msg.warnings) == 0 {
+			_ = writeCache(key, msg)
+		}
+	case errMsg:
+		if cached {
+			repositories := fromCache()
+			repositories.offline = msg.offline
+			return repositories
+		}
+	}
+
+	return msg
+}
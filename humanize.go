@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// ageUnit is a unit ages are counted in, named in full and in the compact
+// form.
+type ageUnit struct {
+	name, short string
+	size        time.Duration
+}
+
+// ageUnits are the units of ages from the smallest to the largest.
+var ageUnits = []ageUnit{
+	{"second", "s", time.Second},
+	{"minute", "m", time.Minute},
+	{"hour", "h", time.Hour},
+	{"day", "d", 24 * time.Hour},
+	{"month", "mo", 30 * 24 * time.Hour},
+	{"year", "y", 365 * 24 * time.Hour},
+}
+
+// age counts d in the largest unit it holds at least one of. Negative
+// durations count zero seconds.
+func age(d time.Duration) (int, ageUnit) {
+	unit := ageUnits[0]
+	for _, u := range ageUnits[1:] {
+		if d < u.size {
+			break
+		}
+		unit = u
+	}
+
+	return max(0, int(d/unit.size)), unit
+}
+
+// timeAgo describes how long before now t was, e.g. "3 days ago".
+func timeAgo(t, now time.Time) string {
+	n, unit := age(now.Sub(t))
+	switch {
+	case unit.size < time.Minute:
+		return "just now"
+	case n == 1:
+		return fmt.Sprintf("1 %s ago", unit.name)
+	default:
+		return fmt.Sprintf("%d %ss ago", n, unit.name)
+	}
+}
+
+// shortAge describes how long before now t was in a compact form such as
+// "2h ago" or "5mo ago". The zero time renders as "-".
+func shortAge(t, now time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+
+	n, unit := age(now.Sub(t))
+	return fmt.Sprintf("%d%s ago", n, unit.short)
+}
+
+// formatInt abbreviates n for display, e.g. 950, 1.2k or 3.4M.
+func formatInt(n int) string {
+	abbreviate := func(value float64, suffix string) string {
+		s := strconv.FormatFloat(value, 'f', 1, 64)
+		return strings.TrimSuffix(s, ".0") + suffix
+	}
+
+	switch {
+	case n >= 1_000_000 || n <= -1_000_000:
+		return abbreviate(float64(n)/1_000_000, "M")
+	case n >= 1_000 || n <= -1_000:
+		return abbreviate(float64(n)/1_000, "k")
+	default:
+		return strconv.Itoa(n)
+	}
+}
+
+// formatSize formats a size given in kilobytes, as the API reports it, e.g.
+// "512 KB" or "3.4 MB". Zero is shown as "empty".
+func formatSize(kb int) string {
+	unit := func(value float64, suffix string) string {
+		s := strconv.FormatFloat(value, 'f', 1, 64)
+		return strings.TrimSuffix(s, ".0") + " " + suffix
+	}
+
+	switch {
+	case kb <= 0:
+		return "empty"
+	case kb >= 1024*1024:
+		return unit(float64(kb)/(1024*1024), "GB")
+	case kb >= 1024:
+		return unit(float64(kb)/1024, "MB")
+	default:
+		return strconv.Itoa(kb) + " KB"
+	}
+}
+
+// truncate shortens s to fit width terminal cells, ending it with "…" when
+// anything was cut. Wide characters are never split.
+func truncate(s string, width int) string {
+	return runewidth.Truncate(s, width, "…")
+}
+
+// singleLine collapses the line breaks and runs of spaces in s, which would
+// otherwise break the table layout.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// cellText makes s safe to lay out in a table cell. Emoji built from several
+// code points, such as ZWJ sequences, skin tones and flags, are measured
+// differently by the table, the padding and the terminal, which pushes the
+// columns after them out of line. They are reduced to code points every one
+// of them agrees on: joiners, variation selectors and modifiers are dropped
+// and flags become their country code.
+func cellText(s string) string {
+	if !strings.ContainsFunc(s, isEmojiPart) {
+		return s
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 0x1F1E6 && r <= 0x1F1FF:
+			// regional indicators map onto the letters A to Z
+			return 'A' + (r - 0x1F1E6)
+		case isEmojiPart(r):
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// isEmojiPart reports whether r only exists to combine with the code points
+// around it into a single emoji.
+func isEmojiPart(r rune) bool {
+	switch {
+	case r == 0x200D: // zero width joiner
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F: // variation selectors
+		return true
+	case r == 0x20E3: // combining keycap
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // skin tone modifiers
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // tag sequences of subdivision flags
+		return true
+	case r >= 0x1F1E6 && r <= 0x1F1FF: // regional indicators
+		return true
+	}
+
+	return false
+}
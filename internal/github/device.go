@@ -0,0 +1,123 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultLoginURL is the site serving the OAuth device flow of github.com.
+const DefaultLoginURL = "https://github.com"
+
+var (
+	// ErrAccessDenied is returned when the user declines a device login.
+	ErrAccessDenied = errors.New("the login was denied")
+	// ErrLoginExpired is returned when a device code expires before the
+	// user approves it.
+	ErrLoginExpired = errors.New("the code expired before the login was approved")
+)
+
+// DeviceCode is what the user needs to approve a device login.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	// ExpiresIn and Interval are in seconds.
+	ExpiresIn int `json:"expires_in"`
+	Interval  int `json:"interval"`
+}
+
+// RequestDeviceCode starts a device login for the OAuth app clientID on the
+// site at loginURL, asking for scope.
+func (c *Client) RequestDeviceCode(ctx context.Context, loginURL, clientID, scope string) (DeviceCode, error) {
+	code := DeviceCode{}
+	err := c.postForm(ctx, loginURL+"/login/device/code", url.Values{
+		"client_id": {clientID},
+		"scope":     {scope},
+	}, &code)
+	if err != nil {
+		return DeviceCode{}, err
+	}
+	if code.DeviceCode == "" {
+		return DeviceCode{}, errors.New("GitHub didn't return a device code, is the client ID right?")
+	}
+
+	return code, nil
+}
+
+// PollDeviceToken waits for the user to approve code and returns the access
+// token it grants. It gives up with ErrLoginExpired once the code expires.
+func (c *Client) PollDeviceToken(ctx context.Context, loginURL, clientID string, code DeviceCode) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(code.ExpiresIn)*time.Second)
+	defer cancel()
+
+	// GitHub asks for at least five seconds between polls
+	interval := time.Duration(max(code.Interval, 5)) * time.Second
+	for {
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return "", ErrLoginExpired
+			}
+			return "", ctx.Err()
+		}
+
+		result := struct {
+			AccessToken      string `json:"access_token"`
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+			Interval         int    `json:"interval"`
+		}{}
+		err := c.postForm(ctx, loginURL+"/login/oauth/access_token", url.Values{
+			"client_id":   {clientID},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		}, &result)
+		if err != nil {
+			return "", err
+		}
+
+		switch result.Error {
+		case "":
+			return result.AccessToken, nil
+		case "authorization_pending":
+		case "slow_down":
+			interval = max(interval+5*time.Second, time.Duration(result.Interval)*time.Second)
+		case "expired_token":
+			return "", ErrLoginExpired
+		case "access_denied":
+			return "", ErrAccessDenied
+		default:
+			return "", fmt.Errorf("login failed: %s", result.ErrorDescription)
+		}
+	}
+}
+
+// postForm posts values to url and decodes the JSON answer into v.
+func (c *Client) postForm(ctx context.Context, url string, values url.Values, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(values.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err = checkResponse(resp); err != nil {
+		return err
+	}
+
+	return decode(resp, v)
+}
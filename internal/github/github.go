@@ -0,0 +1,737 @@
+// Package github is a small client for the parts of the GitHub REST API used
+// to list repositories.
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is the public GitHub REST API.
+const DefaultBaseURL = "https://api.github.com"
+
+// DefaultUserAgent identifies the requests of this client, as GitHub asks
+// every client to.
+const DefaultUserAgent = "go-repositories"
+
+// DefaultRetries is how many times a request failing with a network error or
+// a 5xx status is retried.
+const DefaultRetries = 3
+
+// retryBackoff is the delay before the first retry, doubled on each attempt.
+const retryBackoff = 200 * time.Millisecond
+
+// maxRetryAfter is the longest Retry-After the client waits out by itself
+// before retrying. Longer waits are left to the user.
+const maxRetryAfter = 5 * time.Second
+
+// retryRoom is how much time a request retried after a Retry-After is given
+// at least; with less left before the deadline the wait isn't worth it.
+const retryRoom = 2 * time.Second
+
+// ErrNotFound is wrapped by the StatusError returned when the API answers 404.
+var ErrNotFound = errors.New("not found")
+
+// PageError is returned along with the repositories of the earlier pages
+// when a page after the first one of a listing fails.
+type PageError struct {
+	Page int
+	Err  error
+}
+
+func (e *PageError) Error() string { return fmt.Sprintf("page %d failed: %v", e.Page, e.Err) }
+
+func (e *PageError) Unwrap() error { return e.Err }
+
+// StatusError is returned when the API answers with an error status.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	// RetryAfter is how long GitHub's secondary rate limit asked to wait
+	// before trying again.
+	RetryAfter time.Duration
+	msg        string
+	err        error
+}
+
+func (e *StatusError) Error() string { return e.msg }
+
+func (e *StatusError) Unwrap() error { return e.err }
+
+// Client talks to the GitHub REST API.
+type Client struct {
+	HTTP    *http.Client
+	BaseURL string
+	// Token authenticates requests when it is not empty.
+	Token     string
+	Retries   int
+	UserAgent string
+	// Debug, when set, receives every request made and the raw body of its
+	// response.
+	Debug io.Writer
+	// Cache, when set, keeps responses with an ETag so that asking again
+	// sends If-None-Match and an unchanged answer is served from it.
+	Cache ResponseCache
+
+	mu   sync.Mutex
+	rate Rate
+}
+
+// ResponseCache stores response bodies by request URL along with their ETag.
+// It may be used by concurrent requests.
+type ResponseCache interface {
+	Get(url string) (etag string, body []byte, ok bool)
+	Set(url, etag string, body []byte)
+}
+
+// Rate is the API quota as reported by the latest response.
+type Rate struct {
+	Limit     int
+	Remaining int
+}
+
+// Rate returns the quota reported by the latest response, which is zero
+// before any request was made.
+func (c *Client) Rate() Rate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.rate
+}
+
+// NewClient returns a client for the public GitHub API, authenticated with
+// token when it is not empty.
+func NewClient(token string) *Client {
+	return &Client{
+		HTTP:      &http.Client{},
+		BaseURL:   DefaultBaseURL,
+		Token:     token,
+		Retries:   DefaultRetries,
+		UserAgent: DefaultUserAgent,
+	}
+}
+
+// Token returns the personal access token from the environment, if any.
+func Token() string {
+	return strings.TrimSpace(os.Getenv("GITHUB_TOKEN"))
+}
+
+// ListOptions selects which repositories FetchRepos lists.
+type ListOptions struct {
+	// Kind is the kind of account to list. With OwnerAny the users endpoint
+	// is tried first and organizations second.
+	Kind OwnerKind
+	// Starred lists the repositories the user starred instead of owns.
+	Starred bool
+	// Limit stops paginating once that many repositories were fetched. Zero
+	// means no limit.
+	Limit int
+	// Type is "owner", "member" or "all" to choose which repositories of a
+	// user are listed, or empty for the API's default of owned ones. It
+	// doesn't apply to organizations or starred repositories.
+	Type string
+	// Progress, when set, is called after every page with the number of
+	// pages and the repositories fetched so far, which it must not modify.
+	Progress func(pages int, repositories []Repository)
+	// Redirected, when set, is called when the API redirected the listing
+	// elsewhere, as it does for renamed accounts.
+	Redirected func(requested, final string)
+}
+
+// FetchRepos lists the repositories of username selected by opts. The kind of
+// account that answered is returned alongside the repositories.
+func (c *Client) FetchRepos(ctx context.Context, username string, opts ListOptions) ([]Repository, OwnerKind, error) {
+	if opts.Starred {
+		repositories, err := c.starredRepos(ctx, username, opts)
+		return repositories, OwnerUser, err
+	}
+
+	// a later page failing still means the account was found
+	var pageErr *PageError
+	if opts.Kind != OwnerOrg {
+		repositories, err := c.userRepos(ctx, username, opts)
+		if err == nil || errors.As(err, &pageErr) {
+			return repositories, OwnerUser, err
+		}
+		if !errors.Is(err, ErrNotFound) || opts.Kind == OwnerUser {
+			return nil, opts.Kind, err
+		}
+	}
+
+	repositories, err := c.orgRepos(ctx, username, opts)
+	if err != nil && !errors.As(err, &pageErr) {
+		return nil, opts.Kind, err
+	}
+
+	return repositories, OwnerOrg, err
+}
+
+// FetchUserRepos lists the repositories owned by a user.
+func (c *Client) FetchUserRepos(ctx context.Context, username string) ([]Repository, error) {
+	return c.userRepos(ctx, username, ListOptions{})
+}
+
+// FetchOrgRepos lists the repositories of an organization.
+func (c *Client) FetchOrgRepos(ctx context.Context, org string) ([]Repository, error) {
+	return c.orgRepos(ctx, org, ListOptions{})
+}
+
+// FetchStarredRepos lists the repositories a user starred.
+func (c *Client) FetchStarredRepos(ctx context.Context, username string) ([]Repository, error) {
+	return c.starredRepos(ctx, username, ListOptions{})
+}
+
+func (c *Client) userRepos(ctx context.Context, username string, opts ListOptions) ([]Repository, error) {
+	url := c.BaseURL + "/users/" + username + "/repos" + pageQuery(opts.Limit)
+	if opts.Type != "" {
+		url += "&type=" + opts.Type
+	}
+
+	// The public endpoint never lists private repositories, so when the
+	// token belongs to the requested user we ask for their own repos.
+	if c.Token != "" {
+		login, err := c.authenticatedUser(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(login, username) {
+			// type and affiliation can't be combined
+			filter := "&affiliation=owner"
+			if opts.Type != "" {
+				filter = "&type=" + opts.Type
+			}
+			url = c.BaseURL + "/user/repos" + pageQuery(opts.Limit) + filter
+		}
+	}
+
+	return c.fetchPages(ctx, url, opts)
+}
+
+func (c *Client) orgRepos(ctx context.Context, org string, opts ListOptions) ([]Repository, error) {
+	return c.fetchPages(ctx, c.BaseURL+"/orgs/"+org+"/repos"+pageQuery(opts.Limit), opts)
+}
+
+func (c *Client) starredRepos(ctx context.Context, username string, opts ListOptions) ([]Repository, error) {
+	return c.fetchPages(ctx, c.BaseURL+"/users/"+username+"/starred"+pageQuery(opts.Limit), opts)
+}
+
+// maxPageSize is the largest page the API serves.
+const maxPageSize = 100
+
+// pageQuery returns the query asking for pages as large as needed to reach
+// limit, or as large as possible without one.
+func pageQuery(limit int) string {
+	size := maxPageSize
+	if limit > 0 && limit < size {
+		size = limit
+	}
+
+	return "?per_page=" + strconv.Itoa(size)
+}
+
+// fetchPages follows the pagination links starting at url and returns every
+// repository on every page, or the first opts.Limit ones when it isn't zero.
+// When a page after the first fails, the repositories so far are returned
+// with a *PageError.
+func (c *Client) fetchPages(ctx context.Context, url string, opts ListOptions) ([]Repository, error) {
+	repositories := []Repository{}
+	for pages := 1; url != ""; pages++ {
+		fail := func(err error) ([]Repository, error) {
+			if pages == 1 {
+				return nil, err
+			}
+			return repositories, &PageError{Page: pages, Err: err}
+		}
+
+		resp, err := c.get(ctx, url)
+		if err != nil {
+			return fail(err)
+		}
+		if err = checkResponse(resp); err != nil {
+			resp.Body.Close()
+			return fail(err)
+		}
+
+		// redirects are followed by the HTTP client, leaving only the URL
+		// of the final request as a trace
+		if pages == 1 && opts.Redirected != nil {
+			if final := resp.Request.URL.String(); final != url {
+				opts.Redirected(url, final)
+			}
+		}
+
+		page, err := decodeRepositories(resp, repositories)
+		if err == nil {
+			// the decoder stops at the closing bracket, the rest of the
+			// body must still be read for a caching body to be stored
+			_, err = io.Copy(io.Discard, resp.Body)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return fail(err)
+		}
+		repositories = page
+
+		url = NextPageURL(resp.Header.Get("Link"))
+
+		if opts.Limit > 0 && len(repositories) >= opts.Limit {
+			return repositories[:opts.Limit], nil
+		}
+		if opts.Progress != nil {
+			opts.Progress(pages, repositories)
+		}
+	}
+
+	return repositories, nil
+}
+
+// get performs a GET request, retrying transient failures with exponential
+// backoff. Client errors (4xx) are permanent and returned as is, except that
+// a short Retry-After from the secondary rate limit is waited out once when the
+// deadline of ctx leaves time for the retry.
+func (c *Client) get(ctx context.Context, url string) (*http.Response, error) {
+	waited := false
+	for attempt := 0; ; attempt++ {
+		resp, err := c.do(ctx, url)
+		if err == nil && !waited {
+			if wait, ok := retryAfter(resp); ok && wait <= maxRetryAfter && canWait(ctx, wait) {
+				resp.Body.Close()
+				waited = true
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+				continue
+			}
+		}
+
+		transient := (err != nil && ctx.Err() == nil) || (err == nil && resp.StatusCode >= 500)
+		if !transient || attempt >= c.Retries {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(retryBackoff << attempt):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// canWait reports whether ctx leaves time to wait and then retry a request,
+// so that a Retry-After the deadline would cut short is reported instead.
+func canWait(ctx context.Context, wait time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+
+	return !ok || time.Until(deadline) >= wait+retryRoom
+}
+
+// do performs a single GET request, authenticating it when the client has a
+// token.
+func (c *Client) do(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	// the current media type includes topics, which used to need a preview
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	var cached []byte
+	if c.Cache != nil {
+		if etag, body, ok := c.Cache.Get(url); ok {
+			req.Header.Set("If-None-Match", etag)
+			cached = body
+		}
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err == nil {
+		c.recordRate(resp.Header)
+	}
+	if c.Debug != nil {
+		resp, err = c.dump(req, resp, err)
+	}
+	if err == nil && c.Cache != nil {
+		resp, err = c.revalidate(url, resp, cached)
+	}
+
+	return resp, err
+}
+
+// revalidate answers a 304 for url with the cached body, which GitHub
+// doesn't count against the rate limit, and caches fresh responses that
+// carry an ETag once their body has been read to the end.
+func (c *Client) revalidate(url string, resp *http.Response, cached []byte) (*http.Response, error) {
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		resp.Body.Close()
+		resp.StatusCode = http.StatusOK
+		resp.Status = "200 OK (not modified)"
+		resp.Header.Set("Content-Type", "application/json")
+		resp.Body = io.NopCloser(bytes.NewReader(cached))
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		etag := resp.Header.Get("ETag")
+		resp.Body = &cachingBody{ReadCloser: resp.Body, store: func(body []byte) {
+			c.Cache.Set(url, etag, body)
+		}}
+	}
+
+	return resp, nil
+}
+
+// cachingBody copies a response body as it is read and hands the copy to
+// store once the end is reached. A body closed before its end, or one that isn't
+// valid JSON such as a listing cut off halfway, isn't stored.
+type cachingBody struct {
+	io.ReadCloser
+	copy   bytes.Buffer
+	store  func(body []byte)
+	stored bool
+}
+
+func (b *cachingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.copy.Write(p[:n])
+	if err == io.EOF && !b.stored {
+		b.stored = true
+		if json.Valid(b.copy.Bytes()) {
+			b.store(b.copy.Bytes())
+		}
+	}
+
+	return n, err
+}
+
+// dump writes req and the outcome of it to c.Debug. The body of resp is read
+// in full and replaced so it can still be decoded.
+func (c *Client) dump(req *http.Request, resp *http.Response, err error) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	fmt.Fprintf(c.Debug, "%s %s\n", req.Method, req.URL)
+	if err != nil {
+		fmt.Fprintf(c.Debug, "error: %v\n\n", err)
+		return resp, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	fmt.Fprintf(c.Debug, "%s\n%s\n\n", resp.Status, body)
+
+	return resp, err
+}
+
+// recordRate remembers the quota reported by header, if it has one.
+func (c *Client) recordRate(header http.Header) {
+	limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rate = Rate{Limit: limit, Remaining: remaining}
+}
+
+// checkResponse turns error responses from the GitHub API into a
+// *StatusError with a readable message.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+
+	e := &StatusError{
+		Method:     resp.Request.Method,
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		msg:        fmt.Sprintf("GitHub returned status %d", resp.StatusCode),
+	}
+	wait, slowedDown := retryAfter(resp)
+	switch {
+	case isRateLimited(resp):
+		e.msg = "rate limited by GitHub"
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			e.msg = fmt.Sprintf("rate limited, resets at %s", time.Unix(reset, 0).Format("15:04:05"))
+		}
+	case slowedDown:
+		e.RetryAfter = wait
+		e.msg = fmt.Sprintf("GitHub is limiting requests, wait %d seconds and try again", max(1, int(wait/time.Second)))
+	case resp.StatusCode == http.StatusNotFound:
+		e.msg = "not found"
+		e.err = ErrNotFound
+	}
+
+	return e
+}
+
+// snippetSize is how much of an undecodable body is quoted in errors.
+const snippetSize = 200
+
+// decode reads the JSON body of resp into v. When the body isn't JSON, the
+// error quotes its beginning so captive portals and outages are recognizable.
+func decode(resp *http.Response, v any) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "json") {
+		return fmt.Errorf("expected JSON from GitHub but got %s: %s", contentType, snippet(body))
+	}
+	if err = json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("invalid JSON from GitHub (%v): %s", err, snippet(body))
+	}
+
+	return nil
+}
+
+// decodeRepositories appends the repositories in the JSON array body of resp
+// to repositories. They are decoded one at a time as the body streams in
+// rather than once it has been read whole.
+func decodeRepositories(resp *http.Response, repositories []Repository) ([]Repository, error) {
+	head := &headBuffer{}
+	body := io.TeeReader(resp.Body, head)
+	// the snippet quoted once decoding fails starts before the failure
+	fail := func(problem string) ([]Repository, error) {
+		_, _ = io.Copy(io.Discard, io.LimitReader(body, snippetSize+1))
+		return nil, fmt.Errorf("%s: %s", problem, snippet(head.Bytes()))
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "json") {
+		return fail("expected JSON from GitHub but got " + contentType)
+	}
+
+	dec := json.NewDecoder(body)
+	invalid := func(err error) ([]Repository, error) {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		// a body cut short ends in either EOF depending on where it stops
+		truncated := errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		if !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr) && !truncated {
+			return nil, err
+		}
+		return fail(fmt.Sprintf("invalid JSON from GitHub (%v)", err))
+	}
+
+	token, err := dec.Token()
+	if err != nil {
+		return invalid(err)
+	}
+	if token != json.Delim('[') {
+		return fail(fmt.Sprintf("invalid JSON from GitHub (expected an array, got %v)", token))
+	}
+	for dec.More() {
+		repo := Repository{}
+		if err = dec.Decode(&repo); err != nil {
+			return invalid(err)
+		}
+		repositories = append(repositories, repo)
+	}
+	if _, err = dec.Token(); err != nil {
+		return invalid(err)
+	}
+
+	return repositories, nil
+}
+
+// headBuffer keeps the first bytes written to it, one more than a snippet
+// shows so it can still tell whether the snippet is cut short.
+type headBuffer struct {
+	bytes.Buffer
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	if room := snippetSize + 1 - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(room, len(p))])
+	}
+
+	return len(p), nil
+}
+
+// snippet returns the start of body on a single line.
+func snippet(body []byte) string {
+	truncated := len(body) > snippetSize
+	if truncated {
+		body = body[:snippetSize]
+	}
+
+	s := strings.Join(strings.Fields(strings.ToValidUTF8(string(body), "")), " ")
+	if truncated {
+		s += "…"
+	}
+	if s == "" {
+		return "(empty body)"
+	}
+
+	return s
+}
+
+// isRateLimited reports whether the response signals an exhausted rate limit.
+func isRateLimited(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+
+	return resp.Header.Get("X-RateLimit-Remaining") == "0"
+}
+
+// retryAfter returns how long resp asks to wait when it comes from GitHub's
+// secondary rate limit, a 403 or 429 with a Retry-After header in seconds.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// authenticatedUser returns the login of the user the token belongs to.
+func (c *Client) authenticatedUser(ctx context.Context) (string, error) {
+	resp, err := c.get(ctx, c.BaseURL+"/user")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err = checkResponse(resp); err != nil {
+		return "", err
+	}
+
+	user := struct {
+		Login string `json:"login"`
+	}{}
+	if err = decode(resp, &user); err != nil {
+		return "", err
+	}
+
+	return user.Login, nil
+}
+
+// NextPageURL returns the rel="next" URL from a GitHub Link header, or an
+// empty string when there are no more pages.
+func NextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		segments := strings.Split(strings.TrimSpace(part), ";")
+		if len(segments) < 2 {
+			continue
+		}
+
+		for _, param := range segments[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(segments[0]), "<>")
+			}
+		}
+	}
+
+	return ""
+}
+
+// FetchLatestRelease returns the tag of the latest release of the repository
+// called fullName (owner/name), or an empty string when it has none.
+func (c *Client) FetchLatestRelease(ctx context.Context, fullName string) (string, error) {
+	resp, err := c.get(ctx, c.BaseURL+"/repos/"+fullName+"/releases/latest")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err = checkResponse(resp); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	release := struct {
+		TagName string `json:"tag_name"`
+	}{}
+	if err = decode(resp, &release); err != nil {
+		return "", err
+	}
+
+	return release.TagName, nil
+}
+
+// FetchRepo returns the repository called fullName (owner/name).
+func (c *Client) FetchRepo(ctx context.Context, fullName string) (Repository, error) {
+	resp, err := c.get(ctx, c.BaseURL+"/repos/"+fullName)
+	if err != nil {
+		return Repository{}, err
+	}
+	defer resp.Body.Close()
+	if err = checkResponse(resp); err != nil {
+		return Repository{}, err
+	}
+
+	repo := Repository{}
+	if err = decode(resp, &repo); err != nil {
+		return Repository{}, err
+	}
+
+	return repo, nil
+}
+
+// FetchReadme returns the README of the repository called fullName
+// (owner/name), or an empty string when it has none.
+func (c *Client) FetchReadme(ctx context.Context, fullName string) (string, error) {
+	resp, err := c.get(ctx, c.BaseURL+"/repos/"+fullName+"/readme")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err = checkResponse(resp); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	readme := struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}{}
+	if err = decode(resp, &readme); err != nil {
+		return "", err
+	}
+	if readme.Encoding != "base64" {
+		return readme.Content, nil
+	}
+
+	// the content is wrapped at 60 columns
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(readme.Content, "\n", ""))
+	if err != nil {
+		return "", fmt.Errorf("decoding README: %w", err)
+	}
+
+	return string(content), nil
+}
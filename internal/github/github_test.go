@@ -0,0 +1,437 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/YuriBrunetto/go-repositories/internal/githubtest"
+)
+
+func TestDecodeRepositories(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		names       []string
+		// err is a part the error message must contain
+		err string
+	}{
+		{
+			name:        "array",
+			contentType: "application/json; charset=utf-8",
+			body:        `[{"name":"a"},{"name":"b"}]`,
+			names:       []string{"a", "b"},
+		},
+		{
+			name:        "empty array",
+			contentType: "application/json",
+			body:        `[]`,
+			names:       []string{},
+		},
+		{
+			name:        "truncated inside a repository",
+			contentType: "application/json",
+			body:        `[{"name":"a"},{"name":"b","descr`,
+			err:         `invalid JSON from GitHub (unexpected EOF): [{"name":"a"},{"name":"b","descr`,
+		},
+		{
+			name:        "truncated between repositories",
+			contentType: "application/json",
+			body:        `[{"name":"a"},`,
+			err:         `[{"name":"a"},`,
+		},
+		{
+			name:        "empty body",
+			contentType: "application/json",
+			body:        ``,
+			err:         "(empty body)",
+		},
+		{
+			name:        "not an array",
+			contentType: "application/json",
+			body:        `{"message":"Bad credentials"}`,
+			err:         `expected an array`,
+		},
+		{
+			name:        "HTML",
+			contentType: "text/html",
+			body:        "<html>\n  <title>Sign in to the network</title>\n</html>",
+			err:         "expected JSON from GitHub but got text/html: <html> <title>Sign in to the network</title> </html>",
+		},
+		{
+			name:        "long body is cut in the snippet",
+			contentType: "application/json",
+			body:        `[{"name":"` + strings.Repeat("x", 300),
+			err:         strings.Repeat("x", snippetSize-len(`[{"name":"`)) + "…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {tt.contentType}},
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}
+
+			repositories, err := decodeRepositories(resp, []Repository{})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			names := []string{}
+			for _, repo := range repositories {
+				names = append(names, repo.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.names, ",") {
+				t.Errorf("names = %v, want %v", names, tt.names)
+			}
+		})
+	}
+}
+
+// newTestClient starts a fake GitHub and returns a client talking to it,
+// which fails right away on errors rather than retrying them.
+func newTestClient(t *testing.T) (*githubtest.Server, *Client) {
+	t.Helper()
+	server := githubtest.NewServer(t)
+	client := NewClient("")
+	client.BaseURL = server.URL
+	client.Retries = 0
+
+	return server, client
+}
+
+func TestFetchRepos(t *testing.T) {
+	tests := []struct {
+		username string
+		opts     ListOptions
+		repos    int
+		kind     OwnerKind
+		// page is the page of the *PageError returned with the repos
+		page int
+		// status is the status of the *StatusError returned
+		status int
+	}{
+		{username: githubtest.User, repos: 3, kind: OwnerUser},
+		{username: githubtest.User, opts: ListOptions{Limit: 2}, repos: 2, kind: OwnerUser},
+		{username: githubtest.Org, repos: 1, kind: OwnerOrg},
+		{username: githubtest.Org, opts: ListOptions{Kind: OwnerOrg}, repos: 1, kind: OwnerOrg},
+		{username: githubtest.Org, opts: ListOptions{Kind: OwnerUser}, status: http.StatusNotFound},
+		{username: githubtest.Missing, status: http.StatusNotFound},
+		{username: githubtest.RateLimited, status: http.StatusForbidden},
+		{username: githubtest.Flaky, repos: 2, kind: OwnerUser, page: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			_, client := newTestClient(t)
+
+			repositories, kind, err := client.FetchRepos(context.Background(), tt.username, tt.opts)
+			if tt.status != 0 {
+				var statusErr *StatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+					t.Fatalf("error = %v, want a %d", err, tt.status)
+				}
+				if tt.status == http.StatusNotFound && !errors.Is(err, ErrNotFound) {
+					t.Errorf("error = %v, want ErrNotFound", err)
+				}
+				return
+			}
+
+			var pageErr *PageError
+			switch {
+			case tt.page != 0 && (!errors.As(err, &pageErr) || pageErr.Page != tt.page):
+				t.Errorf("error = %v, want page %d to fail", err, tt.page)
+			case tt.page == 0 && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			}
+			if len(repositories) != tt.repos {
+				t.Errorf("got %d repositories, want %d", len(repositories), tt.repos)
+			}
+			if kind != tt.kind {
+				t.Errorf("kind = %v, want %v", kind, tt.kind)
+			}
+		})
+	}
+}
+
+func TestFetchReposFollowsPages(t *testing.T) {
+	server, client := newTestClient(t)
+	pages := []int{}
+	opts := ListOptions{Progress: func(page int, repositories []Repository) {
+		pages = append(pages, page)
+	}}
+
+	repositories, _, err := client.FetchRepos(context.Background(), githubtest.User, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, repo := range repositories {
+		names = append(names, repo.Name)
+	}
+	if want := "hello-world,spoon-knife,linguist"; strings.Join(names, ",") != want {
+		t.Errorf("names = %v, want %s", names, want)
+	}
+	if got := server.Requests("/users/" + githubtest.User + "/repos"); got != 2 {
+		t.Errorf("made %d requests, want 2", got)
+	}
+	if len(pages) != 2 || pages[1] != 2 {
+		t.Errorf("progress reported pages %v, want [1 2]", pages)
+	}
+	if got := client.Rate(); got != (Rate{Limit: 60, Remaining: 59}) {
+		t.Errorf("rate = %+v", got)
+	}
+}
+
+func TestFetchUserReposAuthenticates(t *testing.T) {
+	tests := []struct {
+		login string
+		path  string
+	}{
+		{login: "someone-else", path: "/users/octocat/repos"},
+		// only the owner's own listing includes private repositories
+		{login: "octocat", path: "/user/repos"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.login, func(t *testing.T) {
+			var authorization, path string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authorization = r.Header.Get("Authorization")
+				w.Header().Set("Content-Type", "application/json")
+				if r.URL.Path == "/user" {
+					fmt.Fprintf(w, `{"login":%q}`, tt.login)
+					return
+				}
+				path = r.URL.Path
+				fmt.Fprint(w, `[]`)
+			}))
+			defer server.Close()
+			client := NewClient("secret")
+			client.BaseURL = server.URL
+
+			if _, err := client.FetchUserRepos(context.Background(), "octocat"); err != nil {
+				t.Fatal(err)
+			}
+			if authorization != "Bearer secret" {
+				t.Errorf("Authorization = %q, want the token", authorization)
+			}
+			if path != tt.path {
+				t.Errorf("listed %s, want %s", path, tt.path)
+			}
+		})
+	}
+}
+
+func TestFetchRepo(t *testing.T) {
+	_, client := newTestClient(t)
+
+	repo, err := client.FetchRepo(context.Background(), githubtest.Repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.FullName != githubtest.Repo || repo.StargazersCount != 2500 {
+		t.Errorf("repo = %+v", repo)
+	}
+
+	_, err = client.FetchRepo(context.Background(), githubtest.User+"/missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFetchLatestRelease(t *testing.T) {
+	_, client := newTestClient(t)
+
+	tests := map[string]string{
+		githubtest.Repo:               githubtest.Release,
+		githubtest.User + "/linguist": "",
+	}
+	for fullName, want := range tests {
+		got, err := client.FetchLatestRelease(context.Background(), fullName)
+		if err != nil || got != want {
+			t.Errorf("FetchLatestRelease(%q) = %q, %v, want %q", fullName, got, err, want)
+		}
+	}
+}
+
+func TestGetRetriesServerErrors(t *testing.T) {
+	failures := 2
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"name":"a"}]`)
+	}))
+	defer server.Close()
+	client := NewClient("")
+	client.BaseURL = server.URL
+
+	repositories, err := client.FetchUserRepos(context.Background(), "octocat")
+	if err != nil || len(repositories) != 1 {
+		t.Fatalf("got %d repositories, %v, want 1", len(repositories), err)
+	}
+	if failures != 0 {
+		t.Errorf("%d failures left", failures)
+	}
+}
+
+func TestGetWaitsOutRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		// timeout bounds the request when it isn't zero
+		timeout  time.Duration
+		requests int
+		// wait is the RetryAfter of the *StatusError returned, if any
+		wait time.Duration
+	}{
+		{name: "waited out", retryAfter: "1", requests: 2},
+		{name: "waited out before the deadline", retryAfter: "1", timeout: 5 * time.Second, requests: 2},
+		{name: "past the deadline", retryAfter: "3", timeout: 2 * time.Second, requests: 1, wait: 3 * time.Second},
+		{name: "too long", retryAfter: "60", requests: 1, wait: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests == 1 {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `[{"name":"a"}]`)
+			}))
+			defer server.Close()
+			client := NewClient("")
+			client.BaseURL = server.URL
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			_, err := client.FetchUserRepos(ctx, "octocat")
+			var statusErr *StatusError
+			switch {
+			case tt.wait == 0 && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wait != 0 && (!errors.As(err, &statusErr) || statusErr.RetryAfter != tt.wait):
+				t.Errorf("error = %v, want one asking to wait %v", err, tt.wait)
+			}
+			if tt.wait != 0 && time.Since(start) > time.Second {
+				t.Errorf("took %v to give up, want it done right away", time.Since(start))
+			}
+			if requests != tt.requests {
+				t.Errorf("made %d requests, want %d", requests, tt.requests)
+			}
+		})
+	}
+}
+
+func TestNextPageURL(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"", ""},
+		{`<https://api.github.com/users/a/repos?page=2>; rel="next", <https://api.github.com/users/a/repos?page=5>; rel="last"`, "https://api.github.com/users/a/repos?page=2"},
+		{`<https://api.github.com/users/a/repos?page=1>; rel="prev", <https://api.github.com/users/a/repos?page=1>; rel="first"`, ""},
+		{`<https://api.github.com/users/a/repos?page=4>; rel="last", <https://api.github.com/users/a/repos?page=3>; rel="next"`, "https://api.github.com/users/a/repos?page=3"},
+		{`garbage`, ""},
+	}
+
+	for _, tt := range tests {
+		if got := NextPageURL(tt.link); got != tt.want {
+			t.Errorf("NextPageURL(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
+// mapCache is a ResponseCache in memory.
+type mapCache map[string]struct {
+	etag string
+	body []byte
+}
+
+func (c mapCache) Get(url string) (string, []byte, bool) {
+	r, ok := c[url]
+	return r.etag, r.body, ok
+}
+
+func (c mapCache) Set(url, etag string, body []byte) {
+	c[url] = struct {
+		etag string
+		body []byte
+	}{etag, body}
+}
+
+func TestRevalidate(t *testing.T) {
+	const body = `[{"name":"a"},{"name":"b"}]` + "\n"
+	fresh := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/users/broken/repos":
+			w.Header().Set("ETag", `"broken"`)
+			fmt.Fprint(w, `[{"name":"a"},{"na`)
+		default:
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fresh++
+			w.Header().Set("ETag", `"v1"`)
+			fmt.Fprint(w, body)
+		}
+	}))
+	defer server.Close()
+	cache := mapCache{}
+	client := NewClient("")
+	client.BaseURL = server.URL
+	client.Cache = cache
+
+	for i := 0; i < 2; i++ {
+		repositories, err := client.FetchUserRepos(context.Background(), "octocat")
+		if err != nil || len(repositories) != 2 {
+			t.Fatalf("fetch %d: got %d repositories, %v, want 2", i+1, len(repositories), err)
+		}
+	}
+	if fresh != 1 {
+		t.Errorf("downloaded the listing %d times, want once", fresh)
+	}
+	if got := string(cache[server.URL+"/users/octocat/repos?per_page=100"].body); got != body {
+		t.Errorf("cached %q, want the whole body %q", got, body)
+	}
+
+	if _, err := client.FetchUserRepos(context.Background(), "broken"); err == nil {
+		t.Fatal("a truncated listing decoded")
+	}
+	if _, ok := cache[server.URL+"/users/broken/repos?per_page=100"]; ok {
+		t.Error("a listing that failed to decode was cached")
+	}
+}
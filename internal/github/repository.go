@@ -0,0 +1,55 @@
+package github
+
+import "time"
+
+// Repository is a GitHub repository as returned by the REST API.
+type Repository struct {
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	StargazersCount int    `json:"stargazers_count"`
+	Language        string `json:"language"`
+	Fork            bool   `json:"fork"`
+	Archived        bool   `json:"archived"`
+	Private         bool   `json:"private"`
+	HTMLURL         string `json:"html_url"`
+	// Homepage is the website set on the repository, often without a scheme.
+	Homepage        string `json:"homepage"`
+	FullName        string `json:"full_name"`
+	ForksCount      int    `json:"forks_count"`
+	OpenIssuesCount int    `json:"open_issues_count"`
+	DefaultBranch   string `json:"default_branch"`
+	// Size is in kilobytes.
+	Size      int       `json:"size"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+	Owner     Owner     `json:"owner"`
+	Topics    []string  `json:"topics"`
+	// License is zero when the repository has no license.
+	License License `json:"license"`
+
+	// LatestRelease isn't part of the repository listing; it is filled in
+	// separately with Client.FetchLatestRelease.
+	LatestRelease string `json:"latest_release,omitempty"`
+}
+
+// License is the license GitHub detected for a repository.
+type License struct {
+	// SPDXID is the SPDX identifier such as "MIT", or "NOASSERTION" when
+	// GitHub couldn't tell which license it is.
+	SPDXID string `json:"spdx_id"`
+}
+
+// Owner is the account a repository belongs to.
+type Owner struct {
+	Login string `json:"login"`
+}
+
+// OwnerKind is the kind of account whose repositories are listed.
+type OwnerKind int
+
+const (
+	// OwnerAny tries the users endpoint first and falls back to orgs.
+	OwnerAny OwnerKind = iota
+	OwnerUser
+	OwnerOrg
+)
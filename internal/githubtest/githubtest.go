@@ -0,0 +1,159 @@
+// Package githubtest runs a fake GitHub REST API serving canned fixtures, so
+// code talking to GitHub can be tested without the network. Point a
+// github.Client at it by setting its BaseURL to the server's URL.
+package githubtest
+
+import (
+	"embed"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// The accounts the server knows about. Any other account, repository or
+// path answers 404 like GitHub does.
+const (
+	// User owns three repositories served over two pages linked with a
+	// Link header.
+	User = "octocat"
+	// Org is an organization with a single page of repositories; asking
+	// for it as a user answers 404.
+	Org = "octo-org"
+	// RateLimited answers 403 with an exhausted rate limit.
+	RateLimited = "limited"
+	// Portal answers with an HTML page, as captive portals do.
+	Portal = "portal"
+	// Truncated answers with a JSON body cut off halfway.
+	Truncated = "truncated"
+	// Flaky serves its first page and fails the second one with a 500.
+	Flaky = "flaky"
+	// Missing doesn't exist.
+	Missing = "nobody"
+)
+
+// Repo is the one repository of User that can be fetched on its own, and
+// the only one with a release.
+const Repo = User + "/hello-world"
+
+// Release is the tag of the latest release of Repo.
+const Release = "v1.0.0"
+
+// RateReset is the Unix time the rate limit of RateLimited resets at.
+const RateReset = 1700000000
+
+// Server is a fake GitHub API. It counts the requests made to each path.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+// NewServer starts a Server that is closed when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+
+	s := &Server{requests: map[string]int{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/"+User+"/repos", s.paginated("octocat-page1.json", "octocat-page2.json"))
+	mux.HandleFunc("GET /orgs/"+Org+"/repos", s.paginated("octocat-page2.json"))
+	mux.HandleFunc("GET /users/"+Flaky+"/repos", s.paginated("octocat-page1.json", ""))
+	mux.HandleFunc("GET /users/"+RateLimited+"/repos", s.rateLimited)
+	mux.HandleFunc("GET /users/"+Portal+"/repos", s.fixture("portal.html", "text/html; charset=utf-8"))
+	mux.HandleFunc("GET /users/"+Truncated+"/repos", s.fixture("truncated.json", "application/json"))
+	mux.HandleFunc("GET /repos/"+Repo, s.fixture("hello-world.json", "application/json"))
+	mux.HandleFunc("GET /repos/"+Repo+"/releases/latest", s.fixture("release.json", "application/json"))
+	mux.HandleFunc("/", s.notFound)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.URL.Path]++
+		s.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// Requests returns how many requests were made to path so far.
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[path]
+}
+
+// paginated serves pages, the fixture of page n answering ?page=n, linking
+// each page to the next one. An empty fixture fails its page with a 500.
+func (s *Server) paginated(pages ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page := 1
+		if n, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil {
+			page = n
+		}
+		if page < 1 || page > len(pages) {
+			s.json(w, http.StatusOK, []byte("[]"))
+			return
+		}
+		if pages[page-1] == "" {
+			s.json(w, http.StatusInternalServerError, []byte(`{"message":"Server Error"}`))
+			return
+		}
+
+		if page < len(pages) {
+			next := "http://" + r.Host + r.URL.Path + "?page=" + strconv.Itoa(page+1)
+			last := "http://" + r.Host + r.URL.Path + "?page=" + strconv.Itoa(len(pages))
+			w.Header().Set("Link", `<`+next+`>; rel="next", <`+last+`>; rel="last"`)
+		}
+		s.fixture(pages[page-1], "application/json; charset=utf-8")(w, r)
+	}
+}
+
+// fixture serves the fixture called name as contentType.
+func (s *Server) fixture(name, contentType string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := fixtures.ReadFile("fixtures/" + name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		setRate(w, 59)
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(body)
+	}
+}
+
+func (s *Server) rateLimited(w http.ResponseWriter, r *http.Request) {
+	body, _ := fixtures.ReadFile("fixtures/rate-limited.json")
+	setRate(w, 0)
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(RateReset))
+	s.json(w, http.StatusForbidden, body)
+}
+
+func (s *Server) notFound(w http.ResponseWriter, r *http.Request) {
+	body, _ := fixtures.ReadFile("fixtures/not-found.json")
+	s.json(w, http.StatusNotFound, body)
+}
+
+// json answers status with the JSON body.
+func (s *Server) json(w http.ResponseWriter, status int, body []byte) {
+	if w.Header().Get("X-RateLimit-Remaining") == "" {
+		setRate(w, 59)
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// setRate reports remaining requests out of the anonymous limit of 60.
+func setRate(w http.ResponseWriter, remaining int) {
+	w.Header().Set("X-RateLimit-Limit", "60")
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+}
@@ -0,0 +1,48 @@
+package main
+
+import "github.com/charmbracelet/lipgloss"
+
+// languageColors are GitHub's colors for the most common languages.
+var languageColors = map[string]lipgloss.Color{
+	"C":                "#555555",
+	"C#":               "#178600",
+	"C++":              "#f34b7d",
+	"CSS":              "#563d7c",
+	"Clojure":          "#db5855",
+	"Dart":             "#00B4AB",
+	"Dockerfile":       "#384d54",
+	"Elixir":           "#6e4a7e",
+	"Erlang":           "#B83998",
+	"Go":               "#00ADD8",
+	"HTML":             "#e34c26",
+	"Haskell":          "#5e5086",
+	"Java":             "#b07219",
+	"JavaScript":       "#f1e05a",
+	"Jupyter Notebook": "#DA5B0B",
+	"Kotlin":           "#A97BFF",
+	"Lua":              "#000080",
+	"Nix":              "#7e7eff",
+	"OCaml":            "#3be133",
+	"Objective-C":      "#438eff",
+	"PHP":              "#4F5D95",
+	"Python":           "#3572A5",
+	"Ruby":             "#701516",
+	"Rust":             "#dea584",
+	"Scala":            "#c22d40",
+	"Shell":            "#89e051",
+	"Swift":            "#F05138",
+	"TypeScript":       "#3178c6",
+	"Vim Script":       "#199f4b",
+	"Vue":              "#41b883",
+	"Zig":              "#ec915c",
+}
+
+// languageStyle colors language as GitHub does, or dimly when it has no
+// known color.
+func languageStyle(language string) lipgloss.Style {
+	if color, ok := languageColors[language]; ok {
+		return lipgloss.NewStyle().Foreground(color)
+	}
+
+	return helpStyle
+}
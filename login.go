@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/YuriBrunetto/go-repositories/internal/github"
+)
+
+// loginScope lets the token list private repositories too.
+const loginScope = "repo"
+
+// tokenPath returns the file the token from --login is kept in, under the
+// user config directory.
+func tokenPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "go-repositories", "token"), nil
+}
+
+// loadToken returns the token saved by --login, if any.
+func loadToken() string {
+	path, err := tokenPath()
+	if err != nil {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+// saveToken keeps token where only the current user can read it.
+func saveToken(token string) (string, error) {
+	path, err := tokenPath()
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+
+	return path, os.WriteFile(path, []byte(token+"\n"), 0o600)
+}
+
+// loginURL returns the site serving the device flow for the API at apiURL:
+// github.com for the public API, and the instance itself for Enterprise.
+func loginURL(apiURL string) string {
+	if apiURL == github.DefaultBaseURL {
+		return github.DefaultLoginURL
+	}
+
+	return strings.TrimSuffix(apiURL, "/api/v3")
+}
+
+// login signs in with GitHub's device flow, printing the code to enter, and
+// saves the token for later runs.
+func login(client *github.Client, cfg config) error {
+	if cfg.clientID == "" {
+		return errors.New("--login needs the client ID of an OAuth app with device flow enabled, pass one with --client-id")
+	}
+
+	ctx := context.Background()
+	site := loginURL(client.BaseURL)
+	code, err := client.RequestDeviceCode(ctx, site, cfg.clientID, loginScope)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Open %s and enter the code %s\n", code.VerificationURI, code.UserCode)
+	fmt.Println("Waiting for you to approve the login...")
+
+	token, err := client.PollDeviceToken(ctx, site, cfg.clientID, code)
+	if err != nil {
+		return err
+	}
+	path, err := saveToken(token)
+	if err != nil {
+		return fmt.Errorf("logged in but couldn't save the token: %w", err)
+	}
+	fmt.Println("Logged in, the token is saved to", path)
+
+	return nil
+}
@@ -1,153 +1,791 @@
 package main
 
 import (
-	"encoding/json"
+	"bufio"
+	"context"
+	"flag"
 	"fmt"
-	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
+	"github.com/YuriBrunetto/go-repositories/internal/github"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
 )
 
-var baseStyle = lipgloss.
-	NewStyle().
-	BorderStyle(lipgloss.NormalBorder()).
-	BorderForeground(lipgloss.Color("240"))
+// keyHelp is a keybinding shown in the help footer.
+type keyHelp struct {
+	key  string
+	desc string
+	// short bindings are always shown, the rest only in the full help
+	short bool
+}
+
+var keyHelps = []keyHelp{
+	{"enter", "fetch / details", true},
+	{"esc", "toggle focus", true},
+	{"ctrl+c", "quit (press twice)", true},
+	{"?", "more keys", true},
+	{"↑/↓ j/k", "move", false},
+	// handled by the table's own key map
+	{"g/home", "jump to first", false},
+	{"G/end", "jump to last", false},
+	{"s", "cycle sort", false},
+	{"S", "choose the sort", false},
+	{"R", "reverse the sort", false},
+	{"f", "hide forks", false},
+	{"a", "hide archived", false},
+	{"w", "only repos with a website", false},
+	{"p", "cycle all / public / private repos", false},
+	{"space", "toggle favorite (pinned to the top)", false},
+	{"o", "open in browser", false},
+	{"O", "open every shown repo in the browser", false},
+	{"h", "open the repo's website", false},
+	{"pgdn/up", "scroll the README in details", false},
+	{"/", "filter (re: for a regular expression)", false},
+	{"i", "edit the username", false},
+	{">", "minimum stars (empty clears)", false},
+	{"c", "choose the columns shown", false},
+	{"l", "cycle the language shown", false},
+	{"t", "toggle relative / absolute dates", false},
+	{"z", "toggle compact table", false},
+	{"e", "export to CSV", false},
+	{"m", "copy as a markdown list", false},
+	{"y", "copy URL", false},
+	{"Y", "copy the git clone command", false},
+	{"A", "about", false},
+	{"T", "stats of the fetched repos", false},
+	{"r/ctrl+r/F5", "refresh, skipping the cache, or retry after an error", false},
+	{"ctrl+l", "clear and look up someone else", false},
+	{"ctrl+t", "cycle theme", false},
+	{"*", "toggle starred / owned repos", false},
+}
+
+type Repositories struct {
+	data []github.Repository
+	kind github.OwnerKind
+	// starred is set when these are the repositories the user starred
+	starred bool
+	// cachedAt is set when the repositories were read from the disk cache
+	cachedAt time.Time
+	// offline is set when the cache stands in for an unreachable GitHub
+	offline bool
+	// users is how many users' repositories were merged into data
+	users int
+	// warnings describe the users that failed while others succeeded
+	warnings []string
+	// renames note the usernames GitHub redirected to another account
+	renames []string
+	// limited is set when more repositories exist past the limit
+	limited bool
+	// single is set when data is the one repository asked for by owner/name
+	single bool
+	// rate is the API quota left after fetching
+	rate github.Rate
+}
+
+type sortMode int
 
-var spinnerStyle = lipgloss.
-	NewStyle().
-	Bold(true).
-	Background(lipgloss.Color("57")).
-	Foreground(lipgloss.Color("15"))
+const (
+	sortDefault sortMode = iota
+	sortName
+	sortStars
+	sortUpdated
+	sortCreatedNewest
+	sortCreatedOldest
+	sortLargest
+	sortSmallest
 
-var errorStyle = lipgloss.
-	NewStyle().
-	Bold(true).
-	Background(lipgloss.Color("203")).
-	Foreground(lipgloss.Color("15"))
+	// lastSortMode is the final mode of the cycle
+	lastSortMode = sortSmallest
+)
 
-type Repository struct {
-	Name            string `json:"name"`
-	Description     string `json:"description"`
-	StargazersCount int    `json:"stargazers_count"`
+func (s sortMode) String() string {
+	switch s {
+	case sortName:
+		return "Name (A→Z)"
+	case sortStars:
+		return "Stars (high→low)"
+	case sortUpdated:
+		return "Recently updated"
+	case sortCreatedNewest:
+		return "Newest created"
+	case sortCreatedOldest:
+		return "Oldest created"
+	case sortLargest:
+		return "Size (large→small)"
+	case sortSmallest:
+		return "Size (small→large)"
+	default:
+		return "Default"
+	}
 }
 
-type Repositories struct {
-	data []Repository
+// heading names the field s sorts by with an arrow pointing the way values
+// go down the table, ↓ from high to low, flipped when reversed.
+func (s sortMode) heading(reversed bool) string {
+	var field string
+	descending := true
+	switch s {
+	case sortName:
+		field, descending = "Name", false
+	case sortStars:
+		field = "Stars"
+	case sortUpdated:
+		field = "Updated"
+	case sortCreatedNewest:
+		field = "Created"
+	case sortCreatedOldest:
+		field, descending = "Created", false
+	case sortLargest:
+		field = "Size"
+	case sortSmallest:
+		field, descending = "Size", false
+	default:
+		// the API order has no direction to point at
+		if reversed {
+			return "Default, reversed"
+		}
+		return "Default"
+	}
+
+	if descending != reversed {
+		return field + " ↓"
+	}
+	return field + " ↑"
+}
+
+// next returns the sort mode that follows s in the cycle.
+func (s sortMode) next() sortMode {
+	return (s + 1) % (lastSortMode + 1)
 }
 
+// visibility picks the repositories shown by whether they are private.
+type visibility int
+
+const (
+	visibilityAll visibility = iota
+	visibilityPublic
+	visibilityPrivate
+)
+
 type errMsg struct {
 	err error
+	// rate is the API quota left after the failure
+	rate github.Rate
+	// offline is set when GitHub couldn't be reached at all
+	offline bool
+	// method, url and statusCode describe the failed request, when known
+	method     string
+	url        string
+	statusCode int
 }
 
 func (e errMsg) Error() string { return e.err.Error() }
 
+// request describes the failed request, e.g. "GET /users/foo/repos → 403".
+func (e errMsg) request() string {
+	if e.statusCode == 0 {
+		return ""
+	}
+
+	path := e.url
+	if u, err := url.Parse(e.url); err == nil {
+		path = u.Path
+	}
+
+	return fmt.Sprintf("%s %s → %d", e.method, path, e.statusCode)
+}
+
 type model struct {
 	repositories Repositories
-	textInput    textinput.Model
-	username     string
-	table        table.Model
-	err          error
-	spinner      spinner.Model
-	loading      bool
+	visible      []github.Repository
+	// fetched tells an empty result apart from never having fetched
+	fetched    bool
+	totalStars int
+	sortMode   sortMode
+	// reversed flips the order of the sort mode
+	reversed bool
+	// sortCursor is the mode selected in the sort menu
+	sortCursor   sortMode
+	hideForks    bool
+	hideArchived bool
+	// websiteOnly hides the repositories without a homepage
+	websiteOnly bool
+	visibility  visibility
+	// shownColumns are the titles of the optional columns in the table;
+	// columnCursor is the one selected in the column menu
+	shownColumns map[string]bool
+	columnCursor int
+	// favorites holds the lowercased owner/name of the favorite repositories
+	favorites map[string]bool
+	// compact drops the table borders to fit more rows
+	compact bool
+	// absoluteDates shows dates as dates rather than relative to now
+	absoluteDates bool
+	textInput     textinput.Model
+	filterInput   textinput.Model
+	// filter is the filter the rows are built with. It catches up with
+	// filterInput once typing pauses; filterEdits counts the keystrokes
+	// that changed it so stale pauses are ignored.
+	filter      string
+	filterEdits int
+	// filterError tells why the filter is ignored
+	filterError string
+	starsInput  textinput.Model
+	minStars    int
+	// language shows only repositories in that language when not empty
+	language  string
+	username  string
+	ownerKind github.OwnerKind
+	// starred lists the repositories the user starred instead of owns
+	starred bool
+	// limit caps how many repositories are fetched per user
+	limit int
+	// repoType is the type of user repositories listed, empty for the API's
+	// default of owned ones
+	repoType string
+	client   *github.Client
+	// ctx lives as long as the program; cancel stops in-flight requests
+	ctx    context.Context
+	cancel context.CancelFunc
+	// stopEnrichment cancels the release lookups of the current listing
+	stopEnrichment context.CancelFunc
+	table          table.Model
+	err            error
+	spinner        spinner.Model
+	loading        bool
+	loadingText    string
+	// progress counts what the running fetch has loaded so far; streaming
+	// is set once its first repositories are shown
+	progress  fetchProgress
+	streaming bool
+	// rate is the API quota reported by the latest fetch
+	rate github.Rate
+	// quitPending is set while a second ctrl+c would quit; quitWindows
+	// counts the confirmation windows so stale ones expire nothing
+	quitPending bool
+	quitWindows int
+	// openAllPending is set while O waits to be pressed again to confirm
+	// opening many repositories
+	openAllPending bool
+	// readme scrolls the README of readmeFor, the repository whose details
+	// were last opened; readmeText is its unwrapped text
+	readme     viewport.Model
+	readmeFor  string
+	readmeText string
+	timeout    time.Duration
+	width      int
+	height     int
+	showHelp   bool
+	// showAbout covers the view with the about box
+	showAbout bool
+	// showStats covers the view with the stats screen
+	showStats  bool
+	viewMode   viewMode
+	themeIndex int
+	noColor    bool
+	persist    bool
+	// inputError explains why the username input was rejected
+	inputError string
+	// notice acknowledges a finished action until the next keypress
+	notice string
+}
+
+// config holds the options given on the command line.
+type config struct {
+	theme   string
+	starred bool
+	// user and json print the user's repositories as JSON instead of
+	// starting the interactive UI
+	user string
+	json bool
+	// limit caps how many repositories are fetched per user
+	limit int
+	// noPersist disables remembering the last username
+	noPersist bool
+	// noColor renders the UI without any colors
+	noColor bool
+	// apiURL is the GitHub API to talk to, such as a GitHub Enterprise one
+	apiURL string
+	// repoType selects owned, member or all repositories of users
+	repoType string
+	// spinner names the loading animation
+	spinner string
+	// language shows only repositories in that language
+	language string
+	// userAgent identifies our requests to GitHub and proxies
+	userAgent string
+	// debug logs every API response to a temporary file
+	debug bool
+	// emptyDescription stands in for missing descriptions
+	emptyDescription string
+	// login signs in with the device flow of the OAuth app clientID
+	login    bool
+	clientID string
+	// piped is set when the username was read from stdin, leaving the
+	// terminal for keystrokes
+	piped bool
+}
+
+// pipedUsername returns the first line of stdin when it is piped in rather
+// than a terminal.
+func pipedUsername() (string, bool) {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice != 0 {
+		return "", false
+	}
+
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	return strings.TrimSpace(line), true
+}
+
+func parseFlags() config {
+	cfg := config{}
+	flag.StringVar(&cfg.theme, "theme", os.Getenv("GO_REPOSITORIES_THEME"), "color theme: default, mono or high-contrast")
+	flag.BoolVar(&cfg.noPersist, "no-persist", os.Getenv("GO_REPOSITORIES_NO_PERSIST") != "", "don't remember the last username, the chosen columns or favorites")
+	flag.BoolVar(&cfg.starred, "starred", false, "list starred repositories instead of owned ones")
+	flag.StringVar(&cfg.user, "user", "", "GitHub user or organization to list, also given as an argument or on stdin")
+	flag.BoolVar(&cfg.json, "json", false, "print the repositories of --user as JSON and exit")
+	apiURL := os.Getenv("GITHUB_API_URL")
+	if apiURL == "" {
+		apiURL = github.DefaultBaseURL
+	}
+	flag.StringVar(&cfg.apiURL, "api-url", apiURL, "GitHub API base URL, e.g. https://github.example.com/api/v3")
+	flag.IntVar(&cfg.limit, "limit", 0, "fetch at most this many repositories per user (0 means all)")
+	// any value of NO_COLOR turns colors off, see https://no-color.org
+	_, noColor := os.LookupEnv("NO_COLOR")
+	flag.BoolVar(&cfg.noColor, "no-color", noColor, "render without colors")
+	flag.StringVar(&cfg.repoType, "type", "", "which user repositories to list: owner, member or all (default owner)")
+	userAgent := os.Getenv("GO_REPOSITORIES_USER_AGENT")
+	if userAgent == "" {
+		userAgent = github.DefaultUserAgent
+	}
+	flag.StringVar(&cfg.userAgent, "user-agent", userAgent, "User-Agent header sent to GitHub")
+	flag.BoolVar(&cfg.debug, "debug", false, "log every raw API response to a file, printed on exit")
+	flag.StringVar(&cfg.language, "lang", "", "only show repositories in this language, e.g. Go")
+	flag.StringVar(&cfg.emptyDescription, "empty-description", defaultEmptyDescription, "text shown for repositories without a description")
+	flag.BoolVar(&cfg.login, "login", false, "sign in to GitHub in the browser and save the token for later runs")
+	flag.StringVar(&cfg.clientID, "client-id", os.Getenv("GO_REPOSITORIES_CLIENT_ID"), "client ID of the OAuth app --login signs in with")
+	flag.StringVar(&cfg.spinner, "spinner", "dot", "loading animation: dot, line, minidot, jump, pulse, points, globe or moon")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [username]\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if cfg.user == "" && flag.NArg() > 0 {
+		cfg.user = strings.Join(flag.Args(), ",")
+	}
+	if cfg.user == "" {
+		cfg.user, cfg.piped = pipedUsername()
+	}
+
+	switch cfg.repoType {
+	case "", "owner", "member", "all":
+	default:
+		fmt.Fprintf(os.Stderr, "invalid --type %q: want owner, member or all\n", cfg.repoType)
+		os.Exit(2)
+	}
+
+	return cfg
+}
+
+// programOptions reads keystrokes from the terminal itself when stdin was
+// used up by a piped username.
+func programOptions(cfg config) []tea.ProgramOption {
+	if cfg.piped {
+		return []tea.ProgramOption{tea.WithInputTTY()}
+	}
+
+	return nil
 }
 
 func main() {
-	if _, err := tea.NewProgram(initialModel()).Run(); err != nil {
+	cfg := parseFlags()
+	// the environment wins over a token saved by --login
+	token := github.Token()
+	if token == "" {
+		token = loadToken()
+	}
+	client := github.NewClient(token)
+	client.BaseURL = strings.TrimSuffix(cfg.apiURL, "/")
+	client.UserAgent = cfg.userAgent
+	client.Cache = responseCache{scope: tokenScope(client.Token)}
+	closeDebug := func() {}
+	if cfg.debug {
+		// a file of its own, readable only by the user: responses can hold
+		// private repositories, and a fixed name could be a planted symlink
+		f, err := os.CreateTemp("", "go-repositories-debug-*.log")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		client.Debug = f
+		closeDebug = func() {
+			f.Close()
+			fmt.Fprintln(os.Stderr, "debug log written to", f.Name())
+		}
+	}
+
+	code := 0
+	if cfg.login {
+		if err := login(client, cfg); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			code = 1
+		}
+	} else if cfg.json {
+		if err := printJSON(os.Stdout, client, cfg); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			code = 1
+		}
+	} else if _, err := tea.NewProgram(initialModel(client, cfg), programOptions(cfg)...).Run(); err != nil {
 		fmt.Println("Error running program:", err)
-		os.Exit(1)
+		code = 1
 	}
+
+	// printed once the UI is gone so it doesn't garble the screen
+	closeDebug()
+	os.Exit(code)
 }
 
-func initialModel() model {
+func initialModel(client *github.Client, cfg config) model {
 	// text input
 	ti := textinput.New()
-	ti.Placeholder = "Your GitHub username..."
+	ti.Placeholder = "Your GitHub username (or several, comma-separated, or owner/repo)..."
 	ti.Width = 100
 	ti.Focus()
-
-	// table
-	columns := []table.Column{
-		{Title: "Name", Width: 30},
-		{Title: "Description", Width: 40},
-		{Title: "Stars", Width: 30},
+	saved := settings{}
+	if !cfg.noPersist {
+		saved = loadSettings()
+		ti.SetValue(saved.LastUsername)
 	}
+	if cfg.user != "" {
+		ti.SetValue(cfg.user)
+	}
+
+	// filter input
+	fi := textinput.New()
+	fi.Prompt = "/ "
+	fi.Placeholder = "Filter repositories..."
+	fi.Width = 50
+
+	// minimum stars input
+	si := textinput.New()
+	si.Prompt = "> min stars: "
+	si.Placeholder = "0"
+	si.CharLimit = 9
+	si.Width = 10
+
+	// table, its columns are set by updateRows
 	rows := []table.Row{}
 	t := table.New(
-		table.WithColumns(columns),
 		table.WithRows(rows),
-		table.WithWidth(100),
+		table.WithWidth(defaultWidth),
 	)
-	// table styles
-	ts := table.DefaultStyles()
-	ts.Header = ts.Header.
-		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("240")).
-		BorderBottom(true).
-		Bold(false)
-	ts.Selected = ts.Selected.
-		Foreground(lipgloss.Color("229")).
-		Background(lipgloss.Color("57")).
-		Bold(false)
-	t.SetStyles(ts)
+	// styles
+	emptyDescription = cfg.emptyDescription
+	themeIndex := findTheme(cfg.theme)
+	if cfg.noColor {
+		t.SetStyles(applyTheme(plainTheme))
+	} else {
+		t.SetStyles(applyTheme(themes[themeIndex]))
+	}
 
 	// spinner
 	s := spinner.New()
-	s.Spinner = spinner.Dot
+	s.Spinner = findSpinner(cfg.spinner)
 	// s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 
-	return model{
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m := model{
 		textInput:    ti,
+		filterInput:  fi,
+		starsInput:   si,
 		repositories: Repositories{},
 		err:          nil,
 		table:        t,
 		spinner:      s,
+		client:       client,
+		readme:       viewport.New(0, 0),
+		ctx:          ctx,
+		cancel:       cancel,
+		timeout:      defaultTimeout,
+		themeIndex:   themeIndex,
+		noColor:      cfg.noColor,
+		persist:      !cfg.noPersist,
+		starred:      cfg.starred,
+		limit:        max(0, cfg.limit),
+		repoType:     cfg.repoType,
+		language:     cfg.language,
+		shownColumns: shownColumnsFrom(saved.Columns),
+		favorites:    loadFavorites(),
+	}
+	m.updateRows()
+	if cfg.user != "" {
+		m.submit()
 	}
+
+	return m
+}
+
+// defaultWidth is the table width used until the terminal size is known.
+const defaultWidth = 100
+
+// minWidth and minHeight are the smallest terminal the UI is drawn in.
+const (
+	minWidth  = 40
+	minHeight = 10
+)
+
+// chromeHeight is the number of lines rendered around the table rows: the
+// title, inputs, status lines, table borders and header, the selected
+// description, and the footer.
+const chromeHeight = 14
+
+// compactSaving is how many of those lines the compact table drops: its
+// border and the line under the header.
+const compactSaving = 3
+
+// tableHeight is how many rows of the table fit the terminal.
+func (m model) tableHeight() int {
+	height := m.height - chromeHeight
+	if m.compact {
+		height += compactSaving
+	}
+
+	return max(1, height)
+}
+
+// tableStyles returns the table styles of the current theme and density.
+func (m model) tableStyles() table.Styles {
+	t := plainTheme
+	if !m.noColor {
+		t = themes[m.themeIndex]
+	}
+	styles := applyTheme(t)
+	if m.compact {
+		styles.Header = styles.Header.BorderBottom(false)
+	}
+
+	return styles
 }
 
 func (m model) Init() tea.Cmd {
+	// a username given on the command line is already being looked up
+	if m.loading {
+		return tea.Batch(textinput.Blink, m.fetch(false), m.spinner.Tick)
+	}
+
 	return textinput.Blink
 }
 
+// Update handles msg, then makes sure something takes keystrokes again so
+// no transition, such as a failed first fetch, leaves the user stuck.
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.update(msg)
+	next, ok := updated.(model)
+	if !ok || next.loading || next.viewMode != viewTable || next.table.Focused() || next.inputFocused() {
+		return updated, cmd
+	}
+
+	// a failed fetch is retried with r, which the input would take as text
+	if next.err != nil && next.username != "" {
+		next.table.Focus()
+		return next, cmd
+	}
+
+	return next, tea.Batch(cmd, next.textInput.Focus())
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var (
 		tiCmd      tea.Cmd
+		filterCmd  tea.Cmd
+		starsCmd   tea.Cmd
 		tableCmd   tea.Cmd
 		spinnerCmd tea.Cmd
+		enrichCmd  tea.Cmd
+		pauseCmd   tea.Cmd
 	)
 
 	switch msg := msg.(type) {
 
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+		m.table.SetWidth(m.tableWidth())
+		m.updateRows()
+		m.table.SetHeight(m.tableHeight())
+		m.textInput.Width = max(1, min(defaultWidth, msg.Width-len(m.textInput.Prompt)-1))
+		if repo, ok := m.selectedRepository(); ok {
+			m.sizeReadme(repo)
+		}
+
 	case Repositories:
+		fillKnownReleases(msg.data)
 		m.repositories = msg
-		rows := []table.Row{}
+		m.rate = msg.rate
+		m.fetched = true
+		// starred listings say nothing about whether the owner is an org
+		if !msg.starred {
+			m.ownerKind = msg.kind
+		}
+		m.totalStars = 0
+		for _, repo := range msg.data {
+			m.totalStars += repo.StargazersCount
+		}
+		m.updateRows()
+		// rows already browsed while streaming keep their selection
+		if !m.streaming {
+			m.table.SetCursor(0)
+		}
+		m.table.Focus()
+		m.loading = false
+		m.streaming = false
+		m.progress = fetchProgress{}
+		// the releases of the listing replaced are no longer wanted
+		m.stopEnriching()
+		// releases cost a request per repository, which the anonymous
+		// rate limit can't afford
+		if m.client.Token != "" {
+			ctx, cancel := context.WithCancel(m.ctx)
+			m.stopEnrichment = cancel
+			enrichCmd = enrichRepositories(ctx, m.client, msg.data, m.timeout)
+		}
+		if msg.single && len(msg.data) == 1 {
+			details, cmd := m.openDetails(msg.data[0])
+			return details, tea.Batch(cmd, enrichCmd)
+		}
 
-		for _, repo := range m.repositories.data {
-			description := repo.Description
-			if description == "" {
-				description = "-no description-"
+	case enrichmentMsg:
+		for i := range m.repositories.data {
+			if m.repositories.data[i].FullName == msg.fullName {
+				m.repositories.data[i].LatestRelease = msg.latestRelease
 			}
-			row := table.Row{
-				repo.Name, description, strconv.Itoa(repo.StargazersCount),
-			}
-			rows = append(rows, row)
 		}
+		m.updateRows()
+		return m, msg.next
 
-		m.table.SetRows(rows)
-		m.table.Focus()
-		m.loading = false
+	case progressMsg:
+		m.progress = msg.fetchProgress
+		if len(msg.data) == 0 {
+			return m, msg.next
+		}
+
+		m.repositories = Repositories{data: msg.data, starred: m.starred, users: msg.users}
+		m.fetched = true
+		m.totalStars = 0
+		for _, repo := range msg.data {
+			m.totalStars += repo.StargazersCount
+		}
+		m.updateRows()
+		if !m.streaming {
+			m.streaming = true
+			m.viewMode = viewTable
+			m.table.SetCursor(0)
+			m.table.Focus()
+		}
+		return m, msg.next
+
+	case noticeMsg:
+		m.notice = string(msg)
+
+	case actionErrMsg:
+		m.notice = fmt.Sprintf("couldn't %s: %v", msg.action, msg.err)
+
+	case readmeMsg:
+		// the README of a repository no longer shown is dropped
+		if msg.fullName == m.readmeFor {
+			m.readmeText = msg.text()
+			m.setReadmeContent()
+		}
+		return m, nil
+
+	case filterMsg:
+		// a later keystroke may have restarted the pause
+		if int(msg) == m.filterEdits {
+			m.applyFilter()
+		}
+		return m, nil
+
+	case quitExpiredMsg:
+		// a later window may have started since
+		if int(msg) == m.quitWindows && m.quitPending {
+			m.quitPending = false
+			m.notice = ""
+		}
+		return m, nil
 
 	// keys
 	case tea.KeyMsg:
+		m.notice = ""
+		// opening everything is only confirmed by the very next key
+		confirmOpenAll := m.openAllPending
+		m.openAllPending = false
+		if m.showAbout {
+			return m.updateAbout(msg)
+		}
+		if m.showStats {
+			return m.updateStats(msg)
+		}
+		if m.viewMode == viewColumns {
+			return m.updateColumnsMenu(msg)
+		}
+		if m.viewMode == viewSort {
+			return m.updateSortMenu(msg)
+		}
+		if m.viewMode == viewDetails {
+			switch msg.String() {
+			case "ctrl+c":
+				return m.confirmQuit()
+			case "esc":
+				m.viewMode = viewTable
+			case "o":
+				if repo, ok := m.selectedRepository(); ok {
+					return m, openBrowser(repo.HTMLURL)
+				}
+			case "h":
+				if repo, ok := m.selectedRepository(); ok {
+					return m.openHomepage(repo)
+				}
+			case "Y":
+				if repo, ok := m.selectedRepository(); ok {
+					return m, copyCloneCommand(repo)
+				}
+			default:
+				// everything else scrolls the README
+				var cmd tea.Cmd
+				m.readme, cmd = m.readme.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+
 		switch msg.Type {
 		case tea.KeyEsc:
-			if m.table.Focused() {
+			if m.starsInput.Focused() {
+				m.starsInput.Blur()
+				m.table.Focus()
+				return m, nil
+			} else if m.filterInput.Focused() {
+				m.filterInput.Blur()
+				m.table.Focus()
+			} else if m.table.Focused() {
 				m.table.Blur()
 				m.textInput.Focus()
 			} else {
@@ -156,67 +794,843 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			m.err = nil
 		case tea.KeyCtrlC:
-			return m, tea.Quit
+			return m.confirmQuit()
+		case tea.KeyRunes:
+			// '?' and '*' are never part of a username, so they work from
+			// that input too, but a regular expression filter needs them
+			filtering := m.filterInput.Focused()
+			if msg.String() == "?" && !filtering {
+				m.showHelp = !m.showHelp
+				return m, nil
+			}
+			if msg.String() == "A" && !m.inputFocused() {
+				m.showAbout = true
+				return m, nil
+			}
+			if msg.String() == "T" && !m.inputFocused() {
+				m.showStats = true
+				return m, nil
+			}
+			// 'r' needs to reach us rather than an input
+			if msg.String() == "r" && m.username != "" && !m.loading && !m.inputFocused() {
+				if m.err != nil {
+					m.err = nil
+					m.loading = true
+					m.loadingText = "Retrying..."
+					return m, tea.Batch(m.fetch(false), m.spinner.Tick)
+				}
+				return m.refresh()
+			}
+			if msg.String() == "*" && !filtering {
+				m.starred = !m.starred
+				if m.username == "" || m.loading {
+					return m, nil
+				}
+				m.err = nil
+				m.loading = true
+				m.loadingText = "Fetching repositories..."
+				return m, tea.Batch(m.fetch(false), m.spinner.Tick)
+			}
+			if m.table.Focused() {
+				switch msg.String() {
+				case "s":
+					m.sortMode = m.sortMode.next()
+					m.updateRows()
+					return m, nil
+				case " ":
+					// checked before the table, which pages down on space
+					if repo, ok := m.selectedRepository(); ok {
+						m.toggleFavorite(repo)
+					}
+					return m, nil
+				case "R":
+					m.reversed = !m.reversed
+					m.updateRows()
+					return m, nil
+				case "S":
+					m.sortCursor = m.sortMode
+					m.viewMode = viewSort
+					return m, nil
+				case "f":
+					m.hideForks = !m.hideForks
+					m.updateRows()
+					return m, nil
+				case "a":
+					m.hideArchived = !m.hideArchived
+					m.updateRows()
+					return m, nil
+				case "w":
+					m.websiteOnly = !m.websiteOnly
+					m.updateRows()
+					return m, nil
+				case "p":
+					m.visibility = (m.visibility + 1) % (visibilityPrivate + 1)
+					m.updateRows()
+					return m, nil
+				case "o":
+					if repo, ok := m.selectedRepository(); ok {
+						return m, openBrowser(repo.HTMLURL)
+					}
+					return m, nil
+				case "h":
+					if repo, ok := m.selectedRepository(); ok {
+						return m.openHomepage(repo)
+					}
+					return m, nil
+				case "O":
+					if len(m.visible) > openAllConfirm && !confirmOpenAll {
+						m.openAllPending = true
+						m.notice = fmt.Sprintf("press O again to open all %d repos", len(m.visible))
+						return m, nil
+					}
+					return m, openAll(m.visible)
+				case "/":
+					m.table.Blur()
+					return m, m.filterInput.Focus()
+				case "i":
+					m.table.Blur()
+					return m, m.textInput.Focus()
+				case ">":
+					m.table.Blur()
+					m.starsInput.SetValue("")
+					return m, m.starsInput.Focus()
+				case "c":
+					m.viewMode = viewColumns
+					return m, nil
+				case "l":
+					m.language = nextLanguage(m.repositories.data, m.language)
+					m.updateRows()
+					return m, nil
+				case "z":
+					m.compact = !m.compact
+					m.table.SetStyles(m.tableStyles())
+					if m.height > 0 {
+						m.table.SetHeight(m.tableHeight())
+					}
+					return m, nil
+				case "t":
+					m.absoluteDates = !m.absoluteDates
+					m.updateRows()
+					return m, nil
+				case "e":
+					return m, exportCSV(m.visible)
+				case "m":
+					if len(m.visible) == 0 {
+						return m, nil
+					}
+					return m, copyToClipboard(markdownList(m.visible), fmt.Sprintf("copied %d repos as markdown", len(m.visible)))
+				case "y":
+					if repo, ok := m.selectedRepository(); ok {
+						return m, copyToClipboard(repo.HTMLURL, "copied!")
+					}
+					return m, nil
+				case "Y":
+					if repo, ok := m.selectedRepository(); ok {
+						return m, copyCloneCommand(repo)
+					}
+					return m, nil
+				}
+			}
 		case tea.KeyEnter:
-			m.username = m.textInput.Value()
-			m.textInput.Blur()
-			m.spinner.Tick()
-			m.loading = true
-			return m, tea.Batch(fetchRepositories(m.username), m.spinner.Tick)
+			if m.starsInput.Focused() {
+				value := strings.TrimSpace(m.starsInput.Value())
+				minStars, err := strconv.Atoi(value)
+				if value == "" {
+					minStars, err = 0, nil
+				}
+				if err != nil || minStars < 0 {
+					m.notice = "min stars must be a whole number"
+					return m, nil
+				}
+				m.minStars = minStars
+				m.starsInput.Blur()
+				m.table.Focus()
+				m.updateRows()
+				return m, nil
+			}
+			if m.filterInput.Focused() {
+				m.filterInput.Blur()
+				m.table.Focus()
+				m.applyFilter()
+				return m, nil
+			}
+			if m.table.Focused() {
+				if repo, ok := m.selectedRepository(); ok {
+					return m.openDetails(repo)
+				}
+				return m, nil
+			}
+			// only one fetch runs at a time, so results can't race
+			if m.loading {
+				return m, nil
+			}
+			if !m.submit() {
+				return m, nil
+			}
+			return m, tea.Batch(m.fetch(false), m.spinner.Tick)
+		case tea.KeyCtrlL:
+			// a fetch in flight would fill the table right back in
+			if m.loading {
+				return m, nil
+			}
+			m.clear()
+			return m, m.textInput.Focus()
+		case tea.KeyCtrlT:
+			if m.noColor {
+				m.notice = "themes are off with --no-color"
+				return m, nil
+			}
+			m.themeIndex = (m.themeIndex + 1) % len(themes)
+			m.table.SetStyles(m.tableStyles())
+			m.notice = "theme: " + themes[m.themeIndex].name
+			return m, nil
+		case tea.KeyCtrlR, tea.KeyF5:
+			if m.username == "" || m.loading {
+				return m, nil
+			}
+			return m.refresh()
 		}
 
 	// error
 	case errMsg:
 		m.loading = false
+		m.streaming = false
+		m.progress = fetchProgress{}
+		m.rate = msg.rate
 		m.err = msg
+		return m, nil
 
 	}
 
+	filter := m.filterInput.Value()
 	m.textInput, tiCmd = m.textInput.Update(msg)
+	m.filterInput, filterCmd = m.filterInput.Update(msg)
+	m.starsInput, starsCmd = m.starsInput.Update(msg)
+	if m.filterInput.Value() != filter {
+		m.filterEdits++
+		edit := m.filterEdits
+		pauseCmd = tea.Tick(filterDelay, func(time.Time) tea.Msg {
+			return filterMsg(edit)
+		})
+	}
 	m.table, tableCmd = m.table.Update(msg)
-	m.spinner, spinnerCmd = m.spinner.Update(msg)
+	// dropping ticks while idle ends the spinner's tick loop
+	if m.loading {
+		m.spinner, spinnerCmd = m.spinner.Update(msg)
+	}
 
-	return m, tea.Batch(tiCmd, tableCmd, spinnerCmd)
+	return m, tea.Batch(tiCmd, filterCmd, starsCmd, tableCmd, spinnerCmd, enrichCmd, pauseCmd)
+}
+
+// submit looks up the usernames typed in the input, reporting whether a
+// fetch should start.
+func (m *model) submit() bool {
+	usernames := parseUsernames(m.textInput.Value())
+	if fullName, ok := parseRepoName(m.textInput.Value()); ok {
+		usernames = []string{fullName}
+	}
+	if len(usernames) == 0 {
+		m.inputError = "please enter a username"
+		return false
+	}
+	m.inputError = ""
+	m.textInput.SetValue(strings.Join(usernames, ", "))
+	username := strings.Join(usernames, ",")
+
+	// a new name may be a user or an organization again
+	if username != m.username {
+		m.ownerKind = github.OwnerAny
+	}
+	m.username = username
+	m.err = nil
+	m.textInput.Blur()
+	m.loading = true
+	m.loadingText = "Fetching repositories..."
+
+	return true
+}
+
+// parseUsername trims input and, when it is a GitHub URL, extracts the
+// username from it.
+func parseUsername(input string) string {
+	username := strings.TrimSpace(input)
+	if _, path, ok := strings.Cut(username, "github.com/"); ok {
+		username = path
+		if i := strings.IndexAny(username, "/?#"); i >= 0 {
+			username = username[:i]
+		}
+	}
+
+	return strings.TrimPrefix(username, "@")
+}
+
+// parseRepoName reports whether input names a single repository, as
+// owner/name or as its GitHub URL, and returns it as owner/name.
+func parseRepoName(input string) (string, bool) {
+	name := strings.TrimPrefix(strings.TrimSpace(input), "@")
+	var parts []string
+	if _, path, ok := strings.Cut(name, "github.com/"); ok {
+		if i := strings.IndexAny(path, "?#"); i >= 0 {
+			path = path[:i]
+		}
+		// links into a repository, such as its tree, name it too
+		parts = strings.Split(path, "/")
+		if len(parts) > 2 {
+			parts = parts[:2]
+		}
+		if len(parts) == 2 {
+			parts[1] = strings.TrimSuffix(parts[1], ".git")
+		}
+	} else {
+		parts = strings.Split(name, "/")
+	}
+
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" || strings.Contains(name, ",") {
+		return "", false
+	}
+
+	return parts[0] + "/" + parts[1], true
+}
+
+// parseUsernames splits a comma-separated list of usernames, parsing each
+// one and dropping the empty ones.
+func parseUsernames(input string) []string {
+	usernames := []string{}
+	for _, part := range strings.Split(input, ",") {
+		if username := parseUsername(part); username != "" {
+			usernames = append(usernames, username)
+		}
+	}
+
+	return usernames
+}
+
+// refresh fetches the current username again, skipping the cache. Sorting
+// and filters carry over to the new repositories.
+func (m model) refresh() (tea.Model, tea.Cmd) {
+	m.err = nil
+	m.loading = true
+	m.loadingText = "Refreshing repositories..."
+	// the refreshed pages keep the selected row like the final result does
+	m.streaming = m.fetched
+
+	return m, tea.Batch(m.fetch(true), m.spinner.Tick)
+}
+
+// filterDelay is how long typing in the filter pauses before the rows are
+// rebuilt, which is costly for accounts with many repositories.
+const filterDelay = 150 * time.Millisecond
+
+// filterMsg ends the typing pause started by the filter edit it counts.
+type filterMsg int
+
+// applyFilter rebuilds the rows with the filter as typed so far.
+func (m *model) applyFilter() {
+	if m.filter != m.filterInput.Value() {
+		m.filter = m.filterInput.Value()
+		m.updateRows()
+	}
+}
+
+// quitWindow is how long a second ctrl+c has to follow the first one.
+const quitWindow = time.Second
+
+// quitExpiredMsg ends the quit confirmation window it was started with.
+type quitExpiredMsg int
+
+// confirmQuit quits on a second ctrl+c within quitWindow, and otherwise asks
+// for one.
+func (m model) confirmQuit() (tea.Model, tea.Cmd) {
+	if m.quitPending {
+		return m, m.quit()
+	}
+
+	m.quitPending = true
+	m.quitWindows++
+	m.notice = "press ctrl+c again to quit"
+	window := m.quitWindows
+
+	return m, tea.Tick(quitWindow, func(time.Time) tea.Msg {
+		return quitExpiredMsg(window)
+	})
+}
+
+// quit cancels any requests still in flight and quits the program.
+func (m model) quit() tea.Cmd {
+	m.cancel()
+
+	return tea.Quit
+}
+
+// clear forgets the current user and their repositories, leaving an empty
+// input for the next lookup. Filters and sorting are kept.
+func (m *model) clear() {
+	m.stopEnriching()
+	m.repositories = Repositories{}
+	m.totalStars = 0
+	m.fetched = false
+	m.username = ""
+	m.ownerKind = github.OwnerAny
+	m.err = nil
+	m.inputError = ""
+	m.viewMode = viewTable
+	m.textInput.SetValue("")
+	m.table.Blur()
+	m.updateRows()
+	m.table.SetCursor(0)
+}
+
+// filteredOut reports whether the current filters hide repo. matches is the
+// matcher of the filter pattern.
+func (m model) filteredOut(repo github.Repository, matches func(string) bool) bool {
+	switch {
+	case (m.hideForks && repo.Fork) || (m.hideArchived && repo.Archived):
+		return true
+	case m.websiteOnly && homepageURL(repo) == "":
+		return true
+	case (m.visibility == visibilityPublic && repo.Private) || (m.visibility == visibilityPrivate && !repo.Private):
+		return true
+	case repo.StargazersCount < m.minStars:
+		return true
+	case m.language != "" && !strings.EqualFold(repo.Language, m.language):
+		return true
+	}
+
+	return !matches(repo.Name + " " + repo.Description)
+}
+
+// updateRows rebuilds the table rows from the fetched repositories using the
+// current filters and sort mode, keeping the cursor on the previously selected
+// repository.
+func (m *model) updateRows() {
+	selected, hasSelected := m.selectedRepository()
+
+	matches, err := filterMatcher(m.filter)
+	m.filterError = ""
+	if err != nil {
+		m.filterError = "invalid pattern"
+	}
+	m.visible = []github.Repository{}
+	for _, repo := range m.repositories.data {
+		// a repository asked for by owner/name is shown whatever the
+		// filters say, or looking it up would show nothing at all
+		if !m.repositories.single && m.filteredOut(repo, matches) {
+			continue
+		}
+		m.visible = append(m.visible, repo)
+	}
+
+	switch m.sortMode {
+	case sortName:
+		sort.SliceStable(m.visible, func(i, j int) bool {
+			return naturalLess(m.visible[i].Name, m.visible[j].Name)
+		})
+	case sortStars:
+		sort.SliceStable(m.visible, func(i, j int) bool {
+			a, b := m.visible[i], m.visible[j]
+			// ties are broken by name so equal counts never reshuffle
+			if a.StargazersCount != b.StargazersCount {
+				return a.StargazersCount > b.StargazersCount
+			}
+			return naturalLess(a.Name, b.Name)
+		})
+	case sortUpdated:
+		sort.SliceStable(m.visible, func(i, j int) bool {
+			return m.visible[i].UpdatedAt.After(m.visible[j].UpdatedAt)
+		})
+	case sortCreatedNewest:
+		sort.SliceStable(m.visible, func(i, j int) bool {
+			return m.visible[i].CreatedAt.After(m.visible[j].CreatedAt)
+		})
+	case sortCreatedOldest:
+		sort.SliceStable(m.visible, func(i, j int) bool {
+			return m.visible[i].CreatedAt.Before(m.visible[j].CreatedAt)
+		})
+	case sortLargest:
+		sort.SliceStable(m.visible, func(i, j int) bool {
+			return m.visible[i].Size > m.visible[j].Size
+		})
+	case sortSmallest:
+		sort.SliceStable(m.visible, func(i, j int) bool {
+			return m.visible[i].Size < m.visible[j].Size
+		})
+	}
+	if m.reversed {
+		slices.Reverse(m.visible)
+	}
+	m.pinFavorites(m.visible)
+
+	columns := m.columns()
+	rows := []table.Row{}
+	cursor := 0
+	for i, repo := range m.visible {
+		row := make(table.Row, len(columns))
+		for j, c := range columns {
+			row[j] = cellText(c.value(repo))
+		}
+		rows = append(rows, row)
+
+		if hasSelected && repo.FullName == selected.FullName && repo.Name == selected.Name {
+			cursor = i
+		}
+	}
+
+	// clear the rows first so they never render against columns of a
+	// different length
+	m.table.SetRows([]table.Row{})
+	m.table.SetColumns(tableColumns(columns, m.tableWidth()))
+	m.table.SetRows(rows)
+	m.table.SetCursor(cursor)
+}
+
+// regexpPrefix starts a filter that is a regular expression rather than a
+// fuzzy pattern.
+const regexpPrefix = "re:"
+
+// filterMatcher returns how filter matches the text of a repository: as a
+// regular expression after regexpPrefix, and fuzzily otherwise. An invalid
+// expression matches everything and is returned as the error.
+func filterMatcher(filter string) (func(text string) bool, error) {
+	pattern, ok := strings.CutPrefix(filter, regexpPrefix)
+	if !ok {
+		return func(text string) bool { return fuzzyMatch(filter, text) }, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return func(string) bool { return true }, err
+	}
+
+	return re.MatchString, nil
+}
+
+// fuzzyMatch reports whether every rune of pattern appears in text in order,
+// ignoring case.
+func fuzzyMatch(pattern, text string) bool {
+	remaining := []rune(strings.ToLower(pattern))
+	for _, r := range strings.ToLower(text) {
+		if len(remaining) == 0 {
+			break
+		}
+		if r == remaining[0] {
+			remaining = remaining[1:]
+		}
+	}
+
+	return len(remaining) == 0
+}
+
+// naturalLess orders a before b ignoring case and comparing runs of digits
+// by their value, so "repo2" comes before "Repo10". Names differing only in
+// case fall back to a plain comparison to keep the order deterministic.
+func naturalLess(a, b string) bool {
+	x, y := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+	for len(x) > 0 && len(y) > 0 {
+		if unicode.IsDigit(x[0]) && unicode.IsDigit(y[0]) {
+			var nx, ny []rune
+			nx, x = digitRun(x)
+			ny, y = digitRun(y)
+			if len(nx) != len(ny) {
+				return len(nx) < len(ny)
+			}
+			if p, q := string(nx), string(ny); p != q {
+				return p < q
+			}
+			continue
+		}
+		if x[0] != y[0] {
+			return x[0] < y[0]
+		}
+		x, y = x[1:], y[1:]
+	}
+	if len(x) != len(y) {
+		return len(x) < len(y)
+	}
+
+	return a < b
+}
+
+// digitRun splits the leading digits off s, dropping leading zeros, and
+// returns them with the rest of s.
+func digitRun(s []rune) (digits, rest []rune) {
+	i := 0
+	for i < len(s) && unicode.IsDigit(s[i]) {
+		i++
+	}
+	digits = s[:i]
+	for len(digits) > 1 && digits[0] == '0' {
+		digits = digits[1:]
+	}
+
+	return digits, s[i:]
+}
+
+// nextLanguage returns the language after current in the cycle through the
+// languages of repositories, most common first, ending with no language
+// filter.
+func nextLanguage(repositories []github.Repository, current string) string {
+	counts := map[string]int{}
+	for _, repo := range repositories {
+		if repo.Language != "" {
+			counts[repo.Language]++
+		}
+	}
+	languages := make([]string, 0, len(counts))
+	for language := range counts {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if counts[languages[i]] != counts[languages[j]] {
+			return counts[languages[i]] > counts[languages[j]]
+		}
+		return languages[i] < languages[j]
+	})
+
+	if current == "" {
+		if len(languages) == 0 {
+			return ""
+		}
+		return languages[0]
+	}
+	for i, language := range languages {
+		if strings.EqualFold(language, current) && i+1 < len(languages) {
+			return languages[i+1]
+		}
+	}
+
+	return ""
+}
+
+// activeFilters describes the filters currently hiding repositories.
+func (m model) activeFilters() []string {
+	filters := []string{}
+	if m.hideForks {
+		filters = append(filters, "forks")
+	}
+	if m.hideArchived {
+		filters = append(filters, "archived")
+	}
+	if m.minStars > 0 {
+		filters = append(filters, fmt.Sprintf("under %d stars", m.minStars))
+	}
+	if m.websiteOnly {
+		filters = append(filters, "no website")
+	}
+	switch m.visibility {
+	case visibilityPublic:
+		filters = append(filters, "private")
+	case visibilityPrivate:
+		filters = append(filters, "public")
+	}
+
+	return filters
+}
+
+// inputFocused reports whether keystrokes are going to a text input.
+func (m model) inputFocused() bool {
+	return m.textInput.Focused() || m.filterInput.Focused() || m.starsInput.Focused()
+}
+
+// selectedRepository returns the repository under the table cursor.
+func (m model) selectedRepository() (github.Repository, bool) {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.visible) {
+		return github.Repository{}, false
+	}
+
+	return m.visible[cursor], true
 }
 
 func (m model) View() string {
-	var spinnerView, errorView string
+	// the size is unknown until the first WindowSizeMsg
+	if m.width > 0 && (m.width < minWidth || m.height < minHeight) {
+		return fmt.Sprintf("terminal too small — resize to at least %dx%d", minWidth, minHeight)
+	}
+	if m.showAbout {
+		return m.aboutView()
+	}
+	if m.showStats {
+		return m.statsView()
+	}
+
+	var spinnerView, errorView, headerView, filterView string
 
 	if m.loading {
-		spinnerView = spinnerStyle.Render(m.spinner.View() + " Fetching repositories...")
+		spinnerView = spinnerStyle.Render(m.spinner.View() + " " + m.loadingText)
+		if m.progress.pages > 0 {
+			spinnerView += " " + headerStyle.Render(m.progress.String())
+		}
 	} else {
 		spinnerView = ""
 	}
 
 	if m.err != nil {
-		errorView = errorStyle.Render("Error while fetching repositories!")
+		errorView = errorStyle.Render("Error while fetching repositories: " + m.err.Error())
+		if e, ok := m.err.(errMsg); ok && e.request() != "" {
+			errorView += " " + headerStyle.Render(e.request())
+		}
 	} else {
 		errorView = ""
 	}
 
+	if m.fetched && m.repositories.starred {
+		headerView = summaryStyle.Render("⭐ starred repos of "+m.username) + "\n"
+	}
+	for _, warning := range m.repositories.warnings {
+		headerView += warningStyle.Render("⚠ "+warning) + "\n"
+	}
+	for _, rename := range m.repositories.renames {
+		headerView += headerStyle.Render("↪ "+rename) + "\n"
+	}
+	if len(m.repositories.data) > 0 {
+		headerView += m.summaryView()
+		if m.repositories.limited {
+			headerView += headerStyle.Render(fmt.Sprintf(" · showing first %d", m.limit))
+		}
+		headerView += "\n"
+		headerView += headerStyle.Render("Sorted by: " + m.sortMode.heading(m.reversed))
+		if !m.repositories.cachedAt.IsZero() {
+			cached := " · cached "
+			if m.repositories.offline {
+				cached = " · offline, cached "
+			}
+			headerView += headerStyle.Render(cached + m.repositories.cachedAt.Format("15:04"))
+		}
+		if m.repoType != "" && !m.repositories.starred && m.repositories.kind != github.OwnerOrg {
+			headerView += headerStyle.Render(" · Type: " + m.repoType)
+		}
+		if m.language != "" {
+			headerView += headerStyle.Render(" · Language: " + m.language)
+		}
+		if filters := m.activeFilters(); len(filters) > 0 {
+			headerView += headerStyle.Render(" · Hiding: " + strings.Join(filters, ", "))
+		}
+	}
+
+	if m.starsInput.Focused() {
+		filterView += m.starsInput.View() + "\n"
+	}
+	if m.filterInput.Focused() || m.filterInput.Value() != "" {
+		count := headerStyle.Render(fmt.Sprintf("%d/%d", len(m.visible), len(m.repositories.data)))
+		if m.filterError != "" {
+			count += " " + warningStyle.Render(m.filterError)
+		}
+		filterView += fmt.Sprintf("%s %s\n", m.filterInput.View(), count)
+	}
+
+	frame := baseStyle
+	if m.compact {
+		frame = lipgloss.NewStyle()
+	}
+	body := frame.Render(m.styleCells(m.table.View()))
+	// the line is kept even when empty so the layout doesn't jump
+	if repo, ok := m.selectedRepository(); ok && m.viewMode == viewTable {
+		description, clipped := m.clippedDescription(repo)
+		if !clipped {
+			description = ""
+		}
+		body += "\n" + helpStyle.Render(truncate(description, m.tableWidth()+2))
+	}
+	if m.viewMode == viewColumns {
+		body = m.columnsMenuView()
+	} else if m.viewMode == viewSort {
+		body = m.sortMenuView()
+	} else if repo, ok := m.selectedRepository(); ok && m.viewMode == viewDetails {
+		body = m.detailsView(repo) + "\n" + baseStyle.Render(m.readme.View())
+	} else if m.fetched && len(m.repositories.data) == 0 {
+		body = headerStyle.Render(fmt.Sprintf("no repositories found for %s", m.username))
+	} else if !m.fetched {
+		// an empty table before the first fetch looks broken
+		body = ""
+		if !m.loading {
+			body = headerStyle.Render("Enter a username above and press Enter")
+		}
+	}
+
+	inputView := m.textInput.View()
+	if m.inputError != "" {
+		inputView += "\n" + errorStyle.Render(m.inputError)
+	}
+
 	return fmt.Sprintf(
-		"Let's fetch your GitHub repos!\n\n%s\n%s%s\n%s",
-		m.textInput.View(),
+		"Let's fetch your GitHub repos!\n\n%s\n%s%s\n%s%s\n%s\n%s%s",
+		inputView,
 		spinnerView,
 		errorView,
-		baseStyle.Render(m.table.View()),
+		filterView,
+		headerView,
+		body,
+		m.rateView(),
+		m.helpView(),
 	)
 }
 
-func fetchRepositories(username string) tea.Cmd {
-	return func() tea.Msg {
-		s := &http.Client{Timeout: time.Second * 8}
-		resp, err := s.Get("https://api.github.com/users/" + username + "/repos")
-		if err != nil {
-			return errMsg{err}
-		}
-		defer resp.Body.Close()
+// rateView renders the API quota on a line of its own, warning when it runs
+// low. Nothing is rendered before the first fetch.
+func (m model) rateView() string {
+	if m.rate.Limit == 0 {
+		return ""
+	}
 
-		repositories := []Repository{}
-		if err = json.NewDecoder(resp.Body).Decode(&repositories); err != nil {
-			return errMsg{err}
+	style := headerStyle
+	if m.rate.Remaining*10 <= m.rate.Limit {
+		style = warningStyle
+	}
+
+	return style.Render(fmt.Sprintf("API: %d/%d remaining", m.rate.Remaining, m.rate.Limit)) + "\n"
+}
+
+// summaryView renders the repository count and their total stars.
+func (m model) summaryView() string {
+	total := len(m.repositories.data)
+	count := fmt.Sprintf("%s repos", formatThousands(total))
+	if len(m.visible) != total {
+		count = fmt.Sprintf("%s shown / %s total", formatThousands(len(m.visible)), formatThousands(total))
+	}
+
+	return summaryStyle.Render(fmt.Sprintf("%s · %s total stars", count, formatThousands(m.totalStars)))
+}
+
+// formatThousands formats n with commas between groups of three digits.
+func formatThousands(n int) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "," + digits[i:]
+	}
+
+	return sign + digits
+}
+
+// helpView renders the keybindings footer, listing every binding when the
+// full help is toggled on.
+func (m model) helpView() string {
+	var notice string
+	if m.notice != "" {
+		notice = noticeStyle.Render(m.notice) + "\n"
+	}
+
+	if !m.showHelp {
+		entries := []string{}
+		for _, h := range keyHelps {
+			if h.short {
+				entries = append(entries, h.key+": "+h.desc)
+			}
 		}
+		return notice + helpStyle.Render(strings.Join(entries, " • "))
+	}
 
-		return Repositories{data: repositories}
+	width := 0
+	for _, h := range keyHelps {
+		width = max(width, runewidth.StringWidth(h.key))
+	}
+	lines := []string{}
+	for _, h := range keyHelps {
+		lines = append(lines, runewidth.FillRight(h.key, width)+" "+h.desc)
 	}
+	return notice + helpStyle.Render(strings.Join(lines, "\n"))
 }
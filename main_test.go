@@ -0,0 +1,920 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/YuriBrunetto/go-repositories/internal/github"
+	"github.com/YuriBrunetto/go-repositories/internal/githubtest"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// newTestModel returns the model the program starts with, kept away from
+// the settings, favorites and caches of whoever runs the tests.
+func newTestModel(t *testing.T) model {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	return initialModel(github.NewClient(""), config{noPersist: true})
+}
+
+// send feeds msgs to m one after the other, dropping the commands they
+// return so nothing reaches the network.
+func send(t *testing.T, m model, msgs ...tea.Msg) model {
+	t.Helper()
+	for _, msg := range msgs {
+		updated, _ := m.Update(msg)
+		next, ok := updated.(model)
+		if !ok {
+			t.Fatalf("Update(%#v) returned a %T", msg, updated)
+		}
+		m = next
+	}
+
+	return m
+}
+
+// typed is the key message of typing s.
+func typed(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+var testRepositories = Repositories{
+	data: []github.Repository{
+		{Name: "hello-world", FullName: "octocat/hello-world", StargazersCount: 3},
+		{Name: "spoon-knife", FullName: "octocat/spoon-knife", StargazersCount: 7},
+	},
+	kind:  github.OwnerUser,
+	users: 1,
+}
+
+func TestUpdate(t *testing.T) {
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+	esc := tea.KeyMsg{Type: tea.KeyEsc}
+
+	tests := []struct {
+		name         string
+		msgs         []tea.Msg
+		rows         int
+		loading      bool
+		inputFocused bool
+		tableFocused bool
+		err          string
+	}{
+		{
+			name:         "initial",
+			inputFocused: true,
+		},
+		{
+			name:         "enter without a username",
+			msgs:         []tea.Msg{enter},
+			inputFocused: true,
+		},
+		{
+			name:    "enter starts a fetch",
+			msgs:    []tea.Msg{typed("octocat"), enter},
+			loading: true,
+		},
+		{
+			name:         "repositories fill the table",
+			msgs:         []tea.Msg{typed("octocat"), enter, testRepositories},
+			rows:         2,
+			tableFocused: true,
+		},
+		{
+			name:         "error stops loading",
+			msgs:         []tea.Msg{typed("octocat"), enter, errMsg{err: errors.New("user 'octocat' not found")}},
+			tableFocused: true,
+			err:          "user 'octocat' not found",
+		},
+		{
+			name:         "esc moves focus to the input",
+			msgs:         []tea.Msg{typed("octocat"), enter, testRepositories, esc},
+			rows:         2,
+			inputFocused: true,
+		},
+		{
+			name:         "esc moves focus back to the table",
+			msgs:         []tea.Msg{typed("octocat"), enter, testRepositories, esc, esc},
+			rows:         2,
+			tableFocused: true,
+		},
+		{
+			name:         "esc clears the error",
+			msgs:         []tea.Msg{typed("octocat"), enter, errMsg{err: errors.New("boom")}, esc},
+			inputFocused: true,
+		},
+		{
+			name:         "r retries after an error",
+			msgs:         []tea.Msg{typed("octocat"), enter, errMsg{err: errors.New("boom")}, typed("r")},
+			loading:      true,
+			tableFocused: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := send(t, newTestModel(t), tt.msgs...)
+
+			if got := len(m.table.Rows()); got != tt.rows {
+				t.Errorf("rows = %d, want %d", got, tt.rows)
+			}
+			if m.loading != tt.loading {
+				t.Errorf("loading = %v, want %v", m.loading, tt.loading)
+			}
+			if got := m.textInput.Focused(); got != tt.inputFocused {
+				t.Errorf("input focused = %v, want %v", got, tt.inputFocused)
+			}
+			if got := m.table.Focused(); got != tt.tableFocused {
+				t.Errorf("table focused = %v, want %v", got, tt.tableFocused)
+			}
+			err := ""
+			if m.err != nil {
+				err = m.err.Error()
+			}
+			if err != tt.err {
+				t.Errorf("err = %q, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUpdateEnterOpensDetails(t *testing.T) {
+	m := send(t, newTestModel(t), typed("octocat"), tea.KeyMsg{Type: tea.KeyEnter}, testRepositories, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.viewMode != viewDetails {
+		t.Fatalf("view mode = %v, want details", m.viewMode)
+	}
+
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.viewMode != viewTable {
+		t.Errorf("view mode after esc = %v, want the table", m.viewMode)
+	}
+}
+
+func TestSingleLookupIgnoresFilters(t *testing.T) {
+	m := newTestModel(t)
+	m.language = "Rust"
+	m.minStars = 100
+	single := Repositories{
+		data:   []github.Repository{{Name: "hello-world", FullName: githubtest.Repo, Language: "Go", StargazersCount: 3}},
+		kind:   github.OwnerAny,
+		users:  1,
+		single: true,
+	}
+
+	m = send(t, m, tea.WindowSizeMsg{Width: 120, Height: 40}, typed(githubtest.Repo), tea.KeyMsg{Type: tea.KeyEnter}, single)
+	if m.viewMode != viewDetails {
+		t.Fatalf("view mode = %v, want details", m.viewMode)
+	}
+	if view := m.View(); !strings.Contains(view, "Language    Go") {
+		t.Errorf("details of %s aren't shown:\n%s", githubtest.Repo, view)
+	}
+}
+
+func TestUpdateSubmitParsesUsername(t *testing.T) {
+	m := send(t, newTestModel(t), typed(" https://github.com/octocat "), tea.KeyMsg{Type: tea.KeyEnter})
+	if m.username != "octocat" {
+		t.Errorf("username = %q, want %q", m.username, "octocat")
+	}
+}
+
+// newTestClient starts a fake GitHub and returns a client talking to it,
+// which fails right away on errors rather than retrying them.
+func newTestClient(t *testing.T) (*githubtest.Server, *github.Client) {
+	t.Helper()
+	server := githubtest.NewServer(t)
+	client := github.NewClient("")
+	client.BaseURL = server.URL
+	client.Retries = 0
+
+	return server, client
+}
+
+func TestFetchRepositories(t *testing.T) {
+	tests := []struct {
+		username string
+		repos    int
+		kind     github.OwnerKind
+		// warning and err are parts of the first warning and of the error
+		warning string
+		err     string
+	}{
+		{username: githubtest.User, repos: 3, kind: github.OwnerUser},
+		{username: githubtest.Org, repos: 1, kind: github.OwnerOrg},
+		{username: githubtest.Flaky, repos: 2, kind: github.OwnerUser, warning: "partial results: page 2 failed"},
+		{username: githubtest.RateLimited, err: "rate limited, resets at"},
+		{username: githubtest.Missing, err: "user 'nobody' not found"},
+		{username: githubtest.Portal, err: "expected JSON from GitHub but got text/html"},
+		{username: githubtest.Truncated, err: `invalid JSON from GitHub (unexpected EOF): [{"name":"a"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.username, func(t *testing.T) {
+			_, client := newTestClient(t)
+
+			msg := fetchRepositories(context.Background(), client, tt.username, github.ListOptions{}, defaultTimeout)()
+			if tt.err != "" {
+				e, ok := msg.(errMsg)
+				if !ok {
+					t.Fatalf("got %T, want an errMsg", msg)
+				}
+				if !strings.Contains(e.Error(), tt.err) {
+					t.Errorf("error = %q, want it to contain %q", e.Error(), tt.err)
+				}
+				return
+			}
+
+			repositories, ok := msg.(Repositories)
+			if !ok {
+				t.Fatalf("got %v, want Repositories", msg)
+			}
+			if len(repositories.data) != tt.repos {
+				t.Errorf("got %d repositories, want %d", len(repositories.data), tt.repos)
+			}
+			if repositories.kind != tt.kind {
+				t.Errorf("kind = %v, want %v", repositories.kind, tt.kind)
+			}
+			warning := ""
+			if len(repositories.warnings) > 0 {
+				warning = repositories.warnings[0]
+			}
+			if !strings.Contains(warning, tt.warning) || (tt.warning == "") != (warning == "") {
+				t.Errorf("warning = %q, want it to contain %q", warning, tt.warning)
+			}
+		})
+	}
+}
+
+func TestFetchRepositoriesReportsRequest(t *testing.T) {
+	_, client := newTestClient(t)
+
+	msg := fetchRepositories(context.Background(), client, githubtest.RateLimited, github.ListOptions{}, defaultTimeout)()
+	e, ok := msg.(errMsg)
+	if !ok {
+		t.Fatalf("got %T, want an errMsg", msg)
+	}
+	if want := "GET /users/limited/repos → 403"; e.request() != want {
+		t.Errorf("request = %q, want %q", e.request(), want)
+	}
+}
+
+func TestFetchRepositoriesFromCannedServer(t *testing.T) {
+	var requests []*http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		if r.URL.Path != "/users/octocat/repos" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"name":"hello-world","full_name":"octocat/hello-world","description":"My first repository","stargazers_count":42,"language":"Go","owner":{"login":"octocat"}}]`)
+	}))
+	defer server.Close()
+	client := github.NewClient("")
+	client.HTTP = server.Client()
+	client.BaseURL = server.URL
+
+	msg := fetchRepositories(context.Background(), client, "octocat", github.ListOptions{}, defaultTimeout)()
+	repositories, ok := msg.(Repositories)
+	if !ok {
+		t.Fatalf("got %v, want Repositories", msg)
+	}
+
+	want := github.Repository{
+		Name:            "hello-world",
+		FullName:        "octocat/hello-world",
+		Description:     "My first repository",
+		StargazersCount: 42,
+		Language:        "Go",
+		Owner:           github.Owner{Login: "octocat"},
+	}
+	if len(repositories.data) != 1 || !reflect.DeepEqual(repositories.data[0], want) {
+		t.Errorf("repositories = %+v, want [%+v]", repositories.data, want)
+	}
+	if repositories.kind != github.OwnerUser || repositories.users != 1 || repositories.limited {
+		t.Errorf("got kind %v, %d users, limited %v", repositories.kind, repositories.users, repositories.limited)
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("made %d requests, want 1", len(requests))
+	}
+	if got := requests[0].Header.Get("User-Agent"); got != github.DefaultUserAgent {
+		t.Errorf("User-Agent = %q, want %q", got, github.DefaultUserAgent)
+	}
+	if got := requests[0].Header.Get("Authorization"); got != "" {
+		t.Errorf("anonymous request sent Authorization %q", got)
+	}
+}
+
+func TestFetchRepositoriesLimit(t *testing.T) {
+	server, client := newTestClient(t)
+
+	msg := fetchRepositories(context.Background(), client, githubtest.User, github.ListOptions{Limit: 1}, defaultTimeout)()
+	repositories, ok := msg.(Repositories)
+	if !ok {
+		t.Fatalf("got %v, want Repositories", msg)
+	}
+	if len(repositories.data) != 1 || !repositories.limited {
+		t.Errorf("got %d repositories, limited %v, want 1 and limited", len(repositories.data), repositories.limited)
+	}
+	// the one extra repository telling the limit was hit is on the first page
+	if got := server.Requests("/users/" + githubtest.User + "/repos"); got != 1 {
+		t.Errorf("made %d requests, want 1", got)
+	}
+}
+
+func TestFetchRepositoriesTimesOut(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+	client := github.NewClient("")
+	client.BaseURL = server.URL
+
+	msg := fetchRepositories(context.Background(), client, "octocat", github.ListOptions{}, 50*time.Millisecond)()
+	e, ok := msg.(errMsg)
+	if !ok {
+		t.Fatalf("got %T, want an errMsg", msg)
+	}
+	if want := "request timed out after 50ms"; e.Error() != want {
+		t.Errorf("error = %q, want %q", e.Error(), want)
+	}
+}
+
+func TestEnrichRepositories(t *testing.T) {
+	releases.tags = map[string]string{}
+	server, client := newTestClient(t)
+	repositories := []github.Repository{
+		{FullName: githubtest.Repo},
+		{FullName: githubtest.User + "/linguist"},
+	}
+	releasePath := "/repos/" + githubtest.Repo + "/releases/latest"
+
+	cmd := enrichRepositories(context.Background(), client, repositories, defaultTimeout)
+	if got := server.Requests(releasePath); got != 0 {
+		t.Fatalf("made %d requests before the command ran", got)
+	}
+
+	msg, ok := cmd().(enrichmentMsg)
+	if !ok || msg.fullName != githubtest.Repo || msg.latestRelease != githubtest.Release {
+		t.Fatalf("got %+v, want the release of %s", msg, githubtest.Repo)
+	}
+	// the repository without a release sends nothing
+	if next := msg.next(); next != nil {
+		t.Errorf("got %+v after the last release", next)
+	}
+
+	// releases already looked up aren't asked for again
+	if cmd := enrichRepositories(context.Background(), client, repositories, defaultTimeout); cmd != nil {
+		t.Error("repositories whose releases are known were enriched again")
+	}
+	fillKnownReleases(repositories)
+	if repositories[0].LatestRelease != githubtest.Release {
+		t.Errorf("release = %q, want %q", repositories[0].LatestRelease, githubtest.Release)
+	}
+	if got := server.Requests(releasePath); got != 1 {
+		t.Errorf("made %d requests, want 1", got)
+	}
+}
+
+func TestEnrichRepositoriesCancelled(t *testing.T) {
+	releases.tags = map[string]string{}
+	_, client := newTestClient(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cmd := enrichRepositories(ctx, client, []github.Repository{{FullName: githubtest.Repo}}, defaultTimeout)
+	if msg := cmd(); msg != nil {
+		t.Errorf("cancelled enrichment delivered %+v", msg)
+	}
+}
+
+func TestShortAge(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{0, "0s ago"},
+		{59 * time.Second, "59s ago"},
+		{time.Minute, "1m ago"},
+		{59*time.Minute + 59*time.Second, "59m ago"},
+		{time.Hour, "1h ago"},
+		{23*time.Hour + 59*time.Minute, "23h ago"},
+		{day, "1d ago"},
+		{29 * day, "29d ago"},
+		{30 * day, "1mo ago"},
+		{364 * day, "12mo ago"},
+		{365 * day, "1y ago"},
+		{3 * 365 * day, "3y ago"},
+		// clocks running ahead of GitHub's don't show negative ages
+		{-time.Minute, "0s ago"},
+	}
+
+	for _, tt := range tests {
+		if got := shortAge(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("shortAge(now - %v) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+
+	if got := shortAge(time.Time{}, now); got != "-" {
+		t.Errorf("shortAge(zero) = %q, want %q", got, "-")
+	}
+}
+
+func TestTimeAgo(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{59 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{2 * time.Hour, "2 hours ago"},
+		{29 * day, "29 days ago"},
+		{30 * day, "1 month ago"},
+		{365 * day, "1 year ago"},
+		{-time.Minute, "just now"},
+	}
+
+	for _, tt := range tests {
+		if got := timeAgo(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("timeAgo(now - %v) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"apple", "Banana", true},
+		{"Banana", "apple", false},
+		{"Zebra", "alpha", false},
+		{"repo2", "repo10", true},
+		{"repo10", "repo2", false},
+		{"Repo2", "repo10", true},
+		{"v1.9", "v1.10", true},
+		{"repo", "repo1", true},
+		{"repo1", "repo", false},
+		{"repo007", "repo8", true},
+		{"2048", "a", true},
+		// names differing only in case or leading zeros still order one way
+		{"Go", "go", true},
+		{"go", "Go", false},
+		{"repo007", "repo7", true},
+		{"repo7", "repo007", false},
+		{"same", "same", false},
+	}
+
+	for _, tt := range tests {
+		if got := naturalLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("naturalLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestSortModeHeading(t *testing.T) {
+	tests := []struct {
+		mode     sortMode
+		reversed bool
+		want     string
+	}{
+		{sortStars, false, "Stars ↓"},
+		{sortStars, true, "Stars ↑"},
+		{sortName, false, "Name ↑"},
+		{sortName, true, "Name ↓"},
+		{sortCreatedNewest, false, "Created ↓"},
+		{sortCreatedOldest, false, "Created ↑"},
+		{sortSmallest, true, "Size ↓"},
+		{sortDefault, true, "Default, reversed"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.mode.heading(tt.reversed); got != tt.want {
+			t.Errorf("%v.heading(%v) = %q, want %q", tt.mode, tt.reversed, got, tt.want)
+		}
+	}
+}
+
+func TestSortByName(t *testing.T) {
+	m := newTestModel(t)
+	m.sortMode = sortName
+	m.repositories = Repositories{data: []github.Repository{
+		{Name: "repo10"}, {Name: "Zeta"}, {Name: "repo2"}, {Name: "alpha"}, {Name: "Repo1"},
+	}}
+	m.updateRows()
+
+	if got, want := visibleNames(m), "alpha,Repo1,repo2,repo10,Zeta"; got != want {
+		t.Errorf("sorted = %s, want %s", got, want)
+	}
+}
+
+// visibleNames lists the names of the repositories shown, in order.
+func visibleNames(m model) string {
+	names := []string{}
+	for _, repo := range m.visible {
+		names = append(names, repo.Name)
+	}
+
+	return strings.Join(names, ",")
+}
+
+func TestSortByStarsBreaksTies(t *testing.T) {
+	orders := [][]github.Repository{
+		{{Name: "delta", StargazersCount: 5}, {Name: "Bravo", StargazersCount: 5}, {Name: "echo", StargazersCount: 9}, {Name: "alpha", StargazersCount: 5}, {Name: "charlie", StargazersCount: 0}},
+		{{Name: "alpha", StargazersCount: 5}, {Name: "charlie", StargazersCount: 0}, {Name: "echo", StargazersCount: 9}, {Name: "delta", StargazersCount: 5}, {Name: "Bravo", StargazersCount: 5}},
+	}
+
+	for _, data := range orders {
+		m := newTestModel(t)
+		m.sortMode = sortStars
+		m.repositories = Repositories{data: data}
+		m.updateRows()
+		// sorting again must not reshuffle the ties either
+		m.updateRows()
+
+		if got, want := visibleNames(m), "echo,alpha,Bravo,delta,charlie"; got != want {
+			t.Errorf("sorted = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestHelpViewAlignsKeys(t *testing.T) {
+	m := newTestModel(t)
+	m.showHelp = true
+
+	column := -1
+	for _, line := range strings.Split(m.helpView(), "\n") {
+		for _, h := range keyHelps {
+			if !strings.HasPrefix(line, h.key+" ") {
+				continue
+			}
+			start := len(h.key) + strings.Index(line[len(h.key):], h.desc)
+			at := runewidth.StringWidth(line[:start])
+			if column == -1 {
+				column = at
+			}
+			if at != column {
+				t.Errorf("%q starts at column %d, want %d", h.desc, at, column)
+			}
+		}
+	}
+	if column == -1 {
+		t.Fatal("no key found in the help")
+	}
+}
+
+func TestPrintJSON(t *testing.T) {
+	tests := []struct {
+		user  string
+		repos int
+		err   string
+	}{
+		{user: githubtest.User, repos: 3},
+		{user: githubtest.User + "," + githubtest.Org, repos: 4},
+		{user: githubtest.User + ", https://github.com/" + githubtest.Org, repos: 4},
+		{user: githubtest.User + "," + githubtest.Missing, err: "user 'nobody' not found"},
+		{user: " , ", err: "--json needs a username"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.user, func(t *testing.T) {
+			_, client := newTestClient(t)
+			var out bytes.Buffer
+
+			err := printJSON(&out, client, config{user: tt.user, json: true})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			repositories := []github.Repository{}
+			if err := json.Unmarshal(out.Bytes(), &repositories); err != nil {
+				t.Fatal(err)
+			}
+			if len(repositories) != tt.repos {
+				t.Errorf("printed %d repositories, want %d", len(repositories), tt.repos)
+			}
+		})
+	}
+}
+
+func TestFilterTakesRegexpCharacters(t *testing.T) {
+	m := send(t, newTestModel(t), typed("octocat"), tea.KeyMsg{Type: tea.KeyEnter}, testRepositories, typed("/"))
+	for _, r := range "re:colou?r.*" {
+		m = send(t, m, typed(string(r)))
+	}
+
+	if got := m.filterInput.Value(); got != "re:colou?r.*" {
+		t.Errorf("filter = %q, want %q", got, "re:colou?r.*")
+	}
+	if m.showHelp || m.starred || m.loading {
+		t.Errorf("typing the filter toggled help %v, starred %v, loading %v", m.showHelp, m.starred, m.loading)
+	}
+
+	// the shortcuts still work from the username input
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEsc}, typed("i"), typed("?"))
+	if !m.showHelp {
+		t.Error("? from the username input didn't show the help")
+	}
+}
+
+func TestFocusRecoversAfterFailedFetch(t *testing.T) {
+	m := newTestModel(t)
+	_, m.client = newTestClient(t)
+
+	m = send(t, m, typed(githubtest.Missing), tea.KeyMsg{Type: tea.KeyEnter})
+	if m.textInput.Focused() || m.table.Focused() {
+		t.Fatal("something kept focus while fetching")
+	}
+
+	msg := m.fetch(false)()
+	if _, ok := msg.(errMsg); !ok {
+		t.Fatalf("fetch returned %T, want an errMsg", msg)
+	}
+	m = send(t, m, msg)
+
+	if !m.table.Focused() {
+		t.Fatal("nothing has focus after the failed fetch")
+	}
+
+	// r retries rather than being typed into the username
+	retried := send(t, m, typed("r"))
+	if !retried.loading || retried.textInput.Value() != githubtest.Missing {
+		t.Errorf("r gave loading %v and username %q, want a retry", retried.loading, retried.textInput.Value())
+	}
+
+	// the username can still be corrected
+	m = send(t, m, tea.KeyMsg{Type: tea.KeyEsc}, tea.KeyMsg{Type: tea.KeyCtrlU}, typed(githubtest.User))
+	if got := m.textInput.Value(); got != githubtest.User {
+		t.Errorf("typed into the input after the error, got %q", got)
+	}
+}
+
+func TestStatsBarsFitTheChart(t *testing.T) {
+	m := newTestModel(t)
+	m.noColor = true
+	m.username = "many"
+	data := []github.Repository{{Name: "a", Language: "Go"}, {Name: "b", Language: "Go"}}
+	for i := 0; i < 20; i++ {
+		data = append(data, github.Repository{Name: fmt.Sprint("lang", i), Language: fmt.Sprint("Lang", i)})
+	}
+	m.repositories = Repositories{data: data}
+
+	counts := m.languageCounts()
+	if last := counts[len(counts)-1]; last.language != "other" || last.count <= counts[0].count {
+		t.Fatalf("counts = %v, want a larger other last", counts)
+	}
+
+	longest := 0
+	for _, line := range strings.Split(m.statsView(), "\n") {
+		longest = max(longest, strings.Count(line, "█"))
+	}
+	if longest != statsBarWidth {
+		t.Errorf("longest bar is %d cells, want %d", longest, statsBarWidth)
+	}
+}
+
+func TestCellText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain text", "plain text"},
+		{"café 日本語", "café 日本語"},
+		{"rocket 🚀 fast", "rocket 🚀 fast"},
+		{"love ❤️ it", "love ❤ it"},
+		{"dev 👨‍💻 tools", "dev 👨💻 tools"},
+		{"thumbs 👍🏽 up", "thumbs 👍 up"},
+		{"made in 🇧🇷", "made in BR"},
+		{"step 1️⃣", "step 1"},
+	}
+
+	for _, tt := range tests {
+		if got := cellText(tt.in); got != tt.want {
+			t.Errorf("cellText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTableColumns(t *testing.T) {
+	columns := []column{nameColumn, descriptionColumn, languageColumn, starsColumn}
+	for _, width := range []int{defaultWidth, 77, 40} {
+		total := 0
+		for _, c := range tableColumns(columns, width) {
+			if c.Width < 1 {
+				t.Errorf("width %d: column %s is %d cells", width, c.Title, c.Width)
+			}
+			total += c.Width
+		}
+		if want := width - 2*len(columns); total != want {
+			t.Errorf("width %d: columns take %d cells, want %d", width, total, want)
+		}
+	}
+}
+
+func TestTableAlignsEmojiDescriptions(t *testing.T) {
+	m := newTestModel(t)
+	m.noColor = true
+	m.table.SetStyles(m.tableStyles())
+	descriptions := []string{
+		"plain text only",
+		"rocket 🚀 launches fast",
+		"made with ❤️ and coffee",
+		"a 👨‍💻 toolbox for developers who type a lot",
+		"café and 日本語 side by side in one description",
+		"🇧🇷 brasileiro",
+		"👍🏽 approved 1️⃣ first",
+	}
+	for i, description := range descriptions {
+		m.repositories.data = append(m.repositories.data, github.Repository{Name: fmt.Sprint("repo", i), FullName: fmt.Sprint("o/repo", i), Description: description})
+	}
+	m.table.SetHeight(len(descriptions) + 2)
+	m.updateRows()
+
+	width := -1
+	for _, line := range strings.Split(m.table.View(), "\n") {
+		// the padding counts code points while truncation counts graphemes,
+		// the two have to agree for the columns to line up
+		if lipgloss.Width(line) != runewidth.StringWidth(line) {
+			t.Errorf("%q is %d cells to the padding but %d to the truncation", line, lipgloss.Width(line), runewidth.StringWidth(line))
+		}
+		if width == -1 {
+			width = lipgloss.Width(line)
+		}
+		if lipgloss.Width(line) != width {
+			t.Errorf("%q is %d cells, want %d", line, lipgloss.Width(line), width)
+		}
+	}
+}
+
+func TestHomepageURL(t *testing.T) {
+	tests := []struct {
+		homepage, want string
+	}{
+		{"", ""},
+		{"  ", ""},
+		{"example.com", "https://example.com"},
+		{"http://example.com/docs?a=1&b=2", "http://example.com/docs?a=1&b=2"},
+		{"https://example.com", "https://example.com"},
+		{"file:///etc/passwd", ""},
+		{"javascript://alert(1)", ""},
+		{"ms-settings://", ""},
+		{"https://", ""},
+		{"example.com/a b|calc", "https://example.com/a%20b%7Ccalc"},
+	}
+
+	for _, tt := range tests {
+		if got := homepageURL(github.Repository{Homepage: tt.homepage}); got != tt.want {
+			t.Errorf("homepageURL(%q) = %q, want %q", tt.homepage, got, tt.want)
+		}
+	}
+}
+
+func TestOpenBrowserRefusesOtherSchemes(t *testing.T) {
+	for _, url := range []string{"file:///etc/passwd", "vscode://open?file=x", "not a url"} {
+		if _, ok := openBrowser(url)().(actionErrMsg); !ok {
+			t.Errorf("openBrowser(%q) didn't refuse it", url)
+		}
+	}
+}
+
+func TestActionErrorKeepsTheFetch(t *testing.T) {
+	progress := progressMsg{data: testRepositories.data, users: 1}
+	failed := actionErrMsg{action: "copy", err: errors.New("no clipboard")}
+	m := send(t, newTestModel(t), typed("octocat"), tea.KeyMsg{Type: tea.KeyEnter}, progress, failed)
+
+	if !m.loading {
+		t.Error("a failed copy stopped the fetch")
+	}
+	if m.err != nil {
+		t.Errorf("err = %v, want the failure in the footer only", m.err)
+	}
+	if want := "couldn't copy: no clipboard"; m.notice != want {
+		t.Errorf("notice = %q, want %q", m.notice, want)
+	}
+}
+
+// freshCache points the disk cache to an empty directory and forgets the
+// session.
+func freshCache(t *testing.T) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	forgetSession()
+}
+
+// forgetSession drops the listings and responses kept in memory, leaving
+// only the disk cache.
+func forgetSession() {
+	session.Lock()
+	defer session.Unlock()
+	clear(session.entries)
+	clear(session.responses)
+}
+
+func TestCacheIsKeptPerAPI(t *testing.T) {
+	freshCache(t)
+	_, client := newTestClient(t)
+	opts := github.ListOptions{}
+
+	// someone else of the same name on github.com
+	other := Repositories{data: []github.Repository{{Name: "elsewhere"}}, kind: github.OwnerUser, users: 1}
+	if err := writeCache(cacheKey(github.NewClient(""), githubtest.User, opts), other); err != nil {
+		t.Fatal(err)
+	}
+	forgetSession()
+
+	msg := loadUser(context.Background(), client, githubtest.User, opts, defaultTimeout, false)
+	repositories, ok := msg.(Repositories)
+	if !ok {
+		t.Fatalf("got %v, want Repositories", msg)
+	}
+	if len(repositories.data) != 3 || !repositories.cachedAt.IsZero() {
+		t.Errorf("got %d repositories cached at %v, want the 3 of the fake API", len(repositories.data), repositories.cachedAt)
+	}
+
+	if _, ok := readCache(cacheKey(github.NewClient(""), githubtest.User, opts)); !ok {
+		t.Error("the github.com listing was dropped")
+	}
+}
+
+func TestCacheIsPrivate(t *testing.T) {
+	freshCache(t)
+	authenticated := github.NewClient("secret")
+	opts := github.ListOptions{}
+	key := cacheKey(authenticated, githubtest.User, opts)
+	if strings.Contains(key, "secret") {
+		t.Fatalf("key %q holds the token", key)
+	}
+
+	private := Repositories{data: []github.Repository{{Name: "private", Private: true}}, kind: github.OwnerUser, users: 1}
+	if err := writeCache(key, private); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := readCache(cacheKey(github.NewClient(""), githubtest.User, opts)); ok {
+		t.Error("an anonymous run was shown the listing fetched with a token")
+	}
+
+	responses := responseCache{scope: tokenScope("secret")}
+	responses.Set("https://api.github.com/user", `"etag"`, []byte(`{"login":"octocat"}`))
+	if _, _, ok := (responseCache{}).Get("https://api.github.com/user"); ok {
+		t.Error("an anonymous run was given the response to a token")
+	}
+	if _, body, ok := responses.Get("https://api.github.com/user"); !ok || string(body) != `{"login":"octocat"}` {
+		t.Errorf("got %q, %v, want the response back", body, ok)
+	}
+
+	listing, _ := cachePath(key)
+	response, _ := responsePath(responses.key("https://api.github.com/user"))
+	for _, path := range []string{listing, response} {
+		for _, p := range []string{path, filepath.Dir(path)} {
+			info, err := os.Stat(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm()&0o077 != 0 {
+				t.Errorf("%s is %v, want only the user to have access", p, info.Mode().Perm())
+			}
+		}
+	}
+}
+
+func TestSessionKeepsFewResponses(t *testing.T) {
+	freshCache(t)
+	for i := 0; i < maxSessionResponses+50; i++ {
+		keepResponse(fmt.Sprint("https://api.github.com/page/", i), cachedResponse{ETag: `"x"`})
+	}
+
+	session.Lock()
+	defer session.Unlock()
+	if len(session.responses) != maxSessionResponses {
+		t.Errorf("session keeps %d responses, want %d", len(session.responses), maxSessionResponses)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/YuriBrunetto/go-repositories/internal/github"
+)
+
+// printJSON writes the repositories of cfg.user to w as JSON. Several
+// comma-separated users have their repositories merged into one list.
+func printJSON(w io.Writer, client *github.Client, cfg config) error {
+	usernames := parseUsernames(cfg.user)
+	if len(usernames) == 0 {
+		return errors.New("--json needs a username, pass one with --user or as an argument")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	repositories := []github.Repository{}
+	for _, username := range usernames {
+		opts := github.ListOptions{Starred: cfg.starred, Limit: max(0, cfg.limit), Type: cfg.repoType}
+		fetched, _, err := client.FetchRepos(ctx, username, opts)
+		if err != nil {
+			return describeError(err, username, defaultTimeout)
+		}
+		repositories = append(repositories, fetched...)
+	}
+	if cfg.language != "" {
+		matching := []github.Repository{}
+		for _, repo := range repositories {
+			if strings.EqualFold(repo.Language, cfg.language) {
+				matching = append(matching, repo)
+			}
+		}
+		repositories = matching
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(repositories)
+}
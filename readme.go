@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/YuriBrunetto/go-repositories/internal/github"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// readmeMsg carries the README of a repository, empty when it has none.
+type readmeMsg struct {
+	fullName string
+	content  string
+	err      error
+}
+
+// fetchReadme loads the README of the repository called fullName.
+func fetchReadme(ctx context.Context, client *github.Client, fullName string, timeout time.Duration) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		content, err := client.FetchReadme(ctx, fullName)
+		return readmeMsg{fullName: fullName, content: content, err: err}
+	}
+}
+
+// detailsChromeHeight is the number of lines rendered around the details
+// view and its README: the title, inputs, status lines, the README border
+// and the footer.
+const detailsChromeHeight = 11
+
+// openDetails shows the details of repo, loading its README unless it is
+// the one already shown.
+func (m model) openDetails(repo github.Repository) (tea.Model, tea.Cmd) {
+	m.viewMode = viewDetails
+	m.sizeReadme(repo)
+	if m.readmeFor == repo.FullName {
+		return m, nil
+	}
+
+	m.readmeFor = repo.FullName
+	m.readmeText = "loading README..."
+	m.setReadmeContent()
+	m.readme.GotoTop()
+
+	return m, fetchReadme(m.ctx, m.client, repo.FullName, m.timeout)
+}
+
+// sizeReadme fits the README viewport in the room the details of repo leave.
+func (m *model) sizeReadme(repo github.Repository) {
+	m.readme.Width = m.detailsWidth()
+	m.readme.Height = 10
+	if m.height > 0 {
+		m.readme.Height = max(3, m.height-detailsChromeHeight-lipgloss.Height(m.detailsView(repo)))
+	}
+	m.setReadmeContent()
+}
+
+// setReadmeContent wraps the README text to the viewport width.
+func (m *model) setReadmeContent() {
+	text := strings.ReplaceAll(m.readmeText, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\t", "    ")
+	m.readme.SetContent(lipgloss.NewStyle().Width(m.readme.Width).Render(text))
+}
+
+// text describes msg for the README viewport.
+func (msg readmeMsg) text() string {
+	switch {
+	case msg.err != nil:
+		return "couldn't load README: " + msg.err.Error()
+	case strings.TrimSpace(msg.content) == "":
+		return "no README"
+	default:
+		return msg.content
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// settings are remembered between runs.
+type settings struct {
+	LastUsername string `json:"last_username"`
+	// Columns are the titles of the optional table columns shown, or nil for
+	// the default ones.
+	Columns []string `json:"columns,omitempty"`
+}
+
+// settingsPath returns the settings file under the user config directory.
+func settingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "go-repositories", "settings.json"), nil
+}
+
+// loadSettings reads the saved settings, returning empty ones when there are
+// none yet.
+func loadSettings() settings {
+	s := settings{}
+	path, err := settingsPath()
+	if err != nil {
+		return s
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return s
+	}
+	_ = json.Unmarshal(data, &s)
+
+	return s
+}
+
+// saveSettings writes s to the settings file.
+func saveSettings(s settings) error {
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
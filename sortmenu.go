@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// updateSortMenu handles keys while the sort menu is open. The selected mode
+// is applied on enter; esc leaves the sort as it was.
+func (m model) updateSortMenu(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.confirmQuit()
+	case "esc", "S":
+		m.viewMode = viewTable
+	case "up", "k":
+		m.sortCursor = max(0, m.sortCursor-1)
+	case "down", "j":
+		m.sortCursor = min(lastSortMode, m.sortCursor+1)
+	case " ", "enter":
+		m.sortMode = m.sortCursor
+		m.viewMode = viewTable
+		m.updateRows()
+	}
+
+	return m, nil
+}
+
+// sortMenuView renders the sort modes, marking the one in use.
+func (m model) sortMenuView() string {
+	lines := []string{labelStyle.Render("Sort by"), ""}
+	for mode := sortDefault; mode <= lastSortMode; mode++ {
+		check := "( )"
+		if mode == m.sortMode {
+			check = "(•)"
+		}
+		line := check + " " + mode.String()
+		if mode == m.sortCursor {
+			line = labelStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	lines = append(lines, "", helpStyle.Render("enter: sort • esc: cancel"))
+
+	return detailsStyle.Render(strings.Join(lines, "\n"))
+}
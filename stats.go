@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// statsLanguages is how many languages the stats chart shows; the rest are
+// counted together.
+const statsLanguages = 8
+
+// statsBarWidth is the width of the longest bar of the chart.
+const statsBarWidth = 30
+
+// updateStats handles keys while the stats screen is open.
+func (m model) updateStats(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m.confirmQuit()
+	case "esc", "T":
+		m.showStats = false
+	}
+
+	return m, nil
+}
+
+// languageCount is how many repositories are written in a language.
+type languageCount struct {
+	language string
+	count    int
+}
+
+// languageCounts counts the repositories of m per language, most common
+// first, folding the languages past statsLanguages into "other", which comes
+// last whatever its count.
+func (m model) languageCounts() []languageCount {
+	counts := map[string]int{}
+	for _, repo := range m.repositories.data {
+		language := repo.Language
+		if language == "" {
+			language = "none"
+		}
+		counts[language]++
+	}
+
+	result := []languageCount{}
+	for language, count := range counts {
+		result = append(result, languageCount{language, count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].count != result[j].count {
+			return result[i].count > result[j].count
+		}
+		return result[i].language < result[j].language
+	})
+	if len(result) > statsLanguages {
+		other := languageCount{language: "other"}
+		for _, c := range result[statsLanguages-1:] {
+			other.count += c.count
+		}
+		result = append(result[:statsLanguages-1], other)
+	}
+
+	return result
+}
+
+// statsView renders totals and a chart of the languages of the fetched
+// repositories, centered in the terminal.
+func (m model) statsView() string {
+	repositories := m.repositories.data
+	lines := []string{labelStyle.Render("Stats for " + m.username), ""}
+	if len(repositories) == 0 {
+		lines = append(lines, "nothing fetched yet")
+	} else {
+		forks := 0
+		top := repositories[0]
+		for _, repo := range repositories {
+			forks += repo.ForksCount
+			if repo.StargazersCount > top.StargazersCount {
+				top = repo
+			}
+		}
+
+		fields := []struct {
+			label string
+			value string
+		}{
+			{"Repos", formatThousands(len(repositories))},
+			{"Stars", formatThousands(m.totalStars)},
+			{"Forks", formatThousands(forks)},
+			{"Most starred", fmt.Sprintf("%s (⭐ %s)", top.Name, formatThousands(top.StargazersCount))},
+		}
+		for _, f := range fields {
+			lines = append(lines, labelStyle.Render(fmt.Sprintf("%-13s", f.label))+f.value)
+		}
+
+		lines = append(lines, "", labelStyle.Render("Languages"))
+		counts := m.languageCounts()
+		// "other" stays last even when it outnumbers the top language
+		width, most := 0, 0
+		for _, c := range counts {
+			width = max(width, len(c.language))
+			most = max(most, c.count)
+		}
+		for _, c := range counts {
+			bar := strings.Repeat("█", max(1, c.count*statsBarWidth/most))
+			if !m.noColor {
+				bar = languageStyle(c.language).Render(bar)
+			}
+			lines = append(lines, fmt.Sprintf("%-*s %s %d", width, c.language, bar, c.count))
+		}
+	}
+	lines = append(lines, "", helpStyle.Render("esc: close"))
+
+	box := detailsStyle.Render(strings.Join(lines, "\n"))
+	// the size is unknown until the first WindowSizeMsg
+	if m.width == 0 {
+		return box
+	}
+
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// theme is a named color palette for the whole UI.
+type theme struct {
+	name string
+	// border colors the table and header borders
+	border lipgloss.Color
+	// accent backgrounds the spinner and the selected row
+	accent lipgloss.Color
+	// onAccent is the text color over accent and error backgrounds
+	onAccent lipgloss.Color
+	selected lipgloss.Color
+	error    lipgloss.Color
+	dim      lipgloss.Color
+	notice   lipgloss.Color
+	strong   lipgloss.Color
+}
+
+var themes = []theme{
+	{
+		name:     "default",
+		border:   "240",
+		accent:   "57",
+		onAccent: "15",
+		selected: "229",
+		error:    "203",
+		dim:      "241",
+		notice:   "42",
+		strong:   "229",
+	},
+	{
+		name:     "mono",
+		border:   "245",
+		accent:   "250",
+		onAccent: "0",
+		selected: "0",
+		error:    "255",
+		dim:      "244",
+		notice:   "252",
+		strong:   "255",
+	},
+	{
+		name:     "high-contrast",
+		border:   "15",
+		accent:   "11",
+		onAccent: "0",
+		selected: "0",
+		error:    "9",
+		dim:      "15",
+		notice:   "10",
+		strong:   "11",
+	},
+}
+
+// plainTheme leaves every color unset, for NO_COLOR and --no-color. It isn't
+// part of themes so cycling never lands on it.
+var plainTheme = theme{name: "no-color"}
+
+// findTheme returns the index of the theme called name, falling back to the
+// default theme.
+func findTheme(name string) int {
+	for i, t := range themes {
+		if strings.EqualFold(t.name, name) {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// spinners are the loading animations to pick from, the first one being the
+// default.
+var spinners = []struct {
+	name    string
+	spinner spinner.Spinner
+}{
+	{"dot", spinner.Dot},
+	{"line", spinner.Line},
+	{"minidot", spinner.MiniDot},
+	{"jump", spinner.Jump},
+	{"pulse", spinner.Pulse},
+	{"points", spinner.Points},
+	{"globe", spinner.Globe},
+	{"moon", spinner.Moon},
+}
+
+// findSpinner returns the spinner called name, falling back to the default
+// one.
+func findSpinner(name string) spinner.Spinner {
+	for _, s := range spinners {
+		if strings.EqualFold(s.name, name) {
+			return s.spinner
+		}
+	}
+
+	return spinners[0].spinner
+}
+
+var (
+	baseStyle    lipgloss.Style
+	spinnerStyle lipgloss.Style
+	errorStyle   lipgloss.Style
+	warningStyle lipgloss.Style
+	headerStyle  lipgloss.Style
+	summaryStyle lipgloss.Style
+	helpStyle    lipgloss.Style
+	noticeStyle  lipgloss.Style
+	detailsStyle lipgloss.Style
+	labelStyle   lipgloss.Style
+	topicStyle   lipgloss.Style
+)
+
+// applyTheme restyles the UI with t and returns the matching table styles.
+func applyTheme(t theme) table.Styles {
+	baseStyle = lipgloss.
+		NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(t.border)
+
+	spinnerStyle = lipgloss.
+		NewStyle().
+		Bold(true).
+		Background(t.accent).
+		Foreground(t.onAccent)
+
+	errorStyle = lipgloss.
+		NewStyle().
+		Bold(true).
+		Background(t.error).
+		Foreground(t.onAccent)
+
+	warningStyle = lipgloss.
+		NewStyle().
+		Foreground(t.error)
+
+	headerStyle = lipgloss.
+		NewStyle().
+		Foreground(t.border)
+
+	summaryStyle = lipgloss.
+		NewStyle().
+		Bold(true).
+		Foreground(t.strong)
+
+	helpStyle = lipgloss.
+		NewStyle().
+		Foreground(t.dim)
+
+	noticeStyle = lipgloss.
+		NewStyle().
+		Foreground(t.notice)
+
+	detailsStyle = lipgloss.
+		NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(t.accent).
+		Padding(1, 2)
+
+	labelStyle = lipgloss.
+		NewStyle().
+		Bold(true).
+		Foreground(t.strong)
+
+	topicStyle = lipgloss.
+		NewStyle().
+		Background(t.accent).
+		Foreground(t.onAccent).
+		Padding(0, 1)
+
+	ts := table.DefaultStyles()
+	ts.Header = ts.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(t.border).
+		BorderBottom(true).
+		Bold(false)
+	ts.Selected = ts.Selected.
+		Foreground(t.selected).
+		Background(t.accent).
+		Bold(false)
+	if t.accent == "" {
+		// without colors the selected row needs another way to stand out
+		ts.Selected = ts.Selected.Reverse(true)
+	}
+
+	return ts
+}